@@ -0,0 +1,185 @@
+package acquire
+
+import (
+	"errors"
+
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/player"
+	"github.com/svera/acquire/tile"
+)
+
+// Action types, as recorded in a game log
+const (
+	PlayTileAction         = "playTile"
+	FoundCorporationAction = "foundCorporation"
+	BuyStockAction         = "buyStock"
+	SellTradeAction        = "sellTrade"
+	UntieMergeAction       = "untieMerge"
+	ClaimEndGameAction     = "claimEndGame"
+	DeactivatePlayerAction = "deactivatePlayer"
+)
+
+const (
+	// UnknownAction is an error returned when trying to apply an action of an unknown type
+	UnknownAction = "unknown_action"
+	// InvalidAction is an error returned when an action references inexistent tiles, corporations or players
+	InvalidAction = "invalid_action"
+	// ActionPlayerMismatch is an error returned when an action is applied on behalf of a player who is not in turn
+	ActionPlayerMismatch = "action_player_mismatch"
+)
+
+// Action stores a successful player action.
+// Corporations are referenced by their position in the corporations array
+// and tiles by their coordinates (see tile.Coords).
+type Action struct {
+	Type string `json:"type"`
+	// Player is the number of the player who did the action, or the deactivated
+	// player in case of DeactivatePlayerAction
+	Player int    `json:"player"`
+	Tile   string `json:"tile,omitempty"`
+	// Corporation is only meaningful in FoundCorporationAction and UntieMergeAction
+	Corporation int         `json:"corporation"`
+	Buy         map[int]int `json:"buy,omitempty"`
+	Sell        map[int]int `json:"sell,omitempty"`
+	Trade       map[int]int `json:"trade,omitempty"`
+}
+
+// Log stores everything needed to reproduce a game created with the default
+// board, corporations, tileset and state machine
+type Log struct {
+	Seed    int64    `json:"seed"`
+	Players int      `json:"players"`
+	Actions []Action `json:"actions"`
+	// HiddenAssets is true in games in which players' cash and stock shares are kept secret
+	HiddenAssets bool `json:"hiddenAssets,omitempty"`
+}
+
+// Log returns the record of all successful actions done in the game so far
+func (g *Game) Log() Log {
+	return Log{
+		Seed:         g.seed,
+		Players:      len(g.players),
+		Actions:      append([]Action{}, g.actions...),
+		HiddenAssets: g.hiddenAssets,
+	}
+}
+
+// Replay creates a new game from the passed log and applies all its actions in order.
+// If an action can not be applied, the game is returned as it was just before it,
+// along with the error. To replay a game up to a certain point, pass a log with
+// its actions truncated.
+func Replay(log Log) (*Game, error) {
+	players := newDefaultPlayers(log.Players)
+	game, err := New(players, Optional{Seed: log.Seed, HiddenAssets: log.HiddenAssets})
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range log.Actions {
+		if err = game.Apply(action); err != nil {
+			return game, err
+		}
+	}
+	return game, nil
+}
+
+// Apply executes the passed action in the game
+func (g *Game) Apply(action Action) error {
+	if action.Type != DeactivatePlayerAction && action.Player != g.currentPlayerNumber {
+		return errors.New(ActionPlayerMismatch)
+	}
+	switch action.Type {
+	case PlayTileAction:
+		tl, err := tile.Parse(action.Tile)
+		if err != nil {
+			return errors.New(InvalidAction)
+		}
+		return g.PlayTile(tl)
+	case FoundCorporationAction:
+		if !isValidCorporationIndex(action.Corporation) {
+			return errors.New(InvalidAction)
+		}
+		return g.FoundCorporation(g.corporations[action.Corporation])
+	case BuyStockAction:
+		buys, err := g.indexesToCorporations(action.Buy)
+		if err != nil {
+			return err
+		}
+		return g.BuyStock(buys)
+	case SellTradeAction:
+		sell, err := g.indexesToCorporations(action.Sell)
+		if err != nil {
+			return err
+		}
+		trade, err := g.indexesToCorporations(action.Trade)
+		if err != nil {
+			return err
+		}
+		_, err = g.SellTrade(sell, trade)
+		return err
+	case UntieMergeAction:
+		if !isValidCorporationIndex(action.Corporation) {
+			return errors.New(InvalidAction)
+		}
+		return g.UntieMerge(g.corporations[action.Corporation])
+	case ClaimEndGameAction:
+		g.ClaimEndGame()
+		return nil
+	case DeactivatePlayerAction:
+		if !g.isValidPlayerNumber(action.Player) {
+			return errors.New(InvalidAction)
+		}
+		g.DeactivatePlayer(g.players[action.Player])
+		return nil
+	}
+	return errors.New(UnknownAction)
+}
+
+// Appends the passed action to the game log, charging the time spent on it
+// if there are time controls and it was made by the player in turn
+func (g *Game) record(action Action) {
+	if g.timeControl.enabled() && action.Player == g.currentPlayerNumber {
+		g.chargeTime(action.Player)
+	}
+	g.actions = append(g.actions, action)
+}
+
+func (g *Game) corporationsToIndexes(amounts map[interfaces.Corporation]int) map[int]int {
+	if len(amounts) == 0 {
+		return nil
+	}
+	indexes := map[int]int{}
+	for corp, amount := range amounts {
+		indexes[g.corporationIndex(corp)] = amount
+	}
+	return indexes
+}
+
+func (g *Game) indexesToCorporations(amounts map[int]int) (map[interfaces.Corporation]int, error) {
+	corps := map[interfaces.Corporation]int{}
+	for index, amount := range amounts {
+		if !isValidCorporationIndex(index) {
+			return nil, errors.New(InvalidAction)
+		}
+		corps[g.corporations[index]] = amount
+	}
+	return corps, nil
+}
+
+// Returns the passed number of players, created with default values
+func newDefaultPlayers(number int) []interfaces.Player {
+	players := []interfaces.Player{}
+	for i := 0; i < number; i++ {
+		players = append(players, player.New())
+	}
+	return players
+}
+
+// Returns the number of the passed player, or -1 if it does not belong to this game
+func (g *Game) playerNumber(pl interfaces.Player) int {
+	for i := range g.players {
+		if g.players[i] == pl {
+			return i
+		}
+	}
+	return -1
+}
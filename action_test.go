@@ -0,0 +1,50 @@
+package acquire
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	game, _ := New(newDefaultPlayers(4), Optional{Seed: 3})
+	playTurns(game, 60)
+
+	data, _ := json.Marshal(game.Log())
+	var log Log
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("Log must be decodable from JSON, got error %s", err)
+	}
+	replayed, err := Replay(log)
+	if err != nil {
+		t.Fatalf("Log must be replayable, got error %s", err)
+	}
+	if !reflect.DeepEqual(game.Snapshot(), replayed.Snapshot()) {
+		t.Errorf("Replayed game state differs from the original one")
+	}
+}
+
+func TestReplayStopsAtFailingAction(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 5})
+	playTurns(game, 10)
+	log := game.Log()
+	log.Actions = append(log.Actions, Action{Type: FoundCorporationAction, Player: game.CurrentPlayerNumber()})
+
+	replayed, err := Replay(log)
+	if err == nil || err.Error() != ActionNotAllowed {
+		t.Errorf("Replaying a not allowed action must return error %s", ActionNotAllowed)
+	}
+	if len(replayed.Log().Actions) != len(log.Actions)-1 {
+		t.Errorf("Replay must stop just before the failing action, expected %d actions, got %d", len(log.Actions)-1, len(replayed.Log().Actions))
+	}
+}
+
+func TestApplyWrongPlayer(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 5})
+	wrongPlayer := (game.CurrentPlayerNumber() + 1) % 3
+	action := Action{Type: PlayTileAction, Player: wrongPlayer, Tile: "1A"}
+
+	if err := game.Apply(action); err == nil || err.Error() != ActionPlayerMismatch {
+		t.Errorf("Applying an action of a player not in turn must return error %s", ActionPlayerMismatch)
+	}
+}
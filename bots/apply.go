@@ -0,0 +1,104 @@
+package bots
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/svera/acquire"
+)
+
+const (
+	// UnknownMessageType is an error message returned when trying to apply a message of an unknown type.
+	UnknownMessageType = "unknown_message_type"
+	// InvalidMessageParams is an error message returned when the params of a message do not
+	// correspond to its type or hold malformed corporation indexes.
+	InvalidMessageParams = "invalid_message_params"
+)
+
+// Apply executes the action described in the passed message on behalf of the player
+// with the passed number, as returned by a bot's Play method.
+// Errors returned by the game, such as acquire.ActionPlayerMismatch
+// or acquire.ActionNotAllowed, are returned as is.
+func Apply(game *acquire.Game, playerNumber int, msg Message) error {
+	action, err := toAction(playerNumber, msg)
+	if err != nil {
+		return err
+	}
+	return game.Apply(action)
+}
+
+// Converts a message into the equivalent game action
+func toAction(playerNumber int, msg Message) (acquire.Action, error) {
+	action := acquire.Action{Player: playerNumber}
+	var err error
+	var ok bool
+
+	switch msg.Type {
+	case PlayTileResponseType:
+		var params PlayTileResponseParams
+		if params, ok = msg.Params.(PlayTileResponseParams); !ok {
+			return action, errors.New(InvalidMessageParams)
+		}
+		action.Type, action.Tile = acquire.PlayTileAction, params.Tile
+	case NewCorpResponseType:
+		var params NewCorpResponseParams
+		if params, ok = msg.Params.(NewCorpResponseParams); !ok {
+			return action, errors.New(InvalidMessageParams)
+		}
+		action.Type, action.Corporation = acquire.FoundCorporationAction, params.CorporationIndex
+	case BuyResponseType:
+		var params BuyResponseParams
+		if params, ok = msg.Params.(BuyResponseParams); !ok {
+			return action, errors.New(InvalidMessageParams)
+		}
+		action.Type = acquire.BuyStockAction
+		action.Buy, err = parseIndexes(params.CorporationsIndexes)
+	case SellTradeResponseType:
+		var params SellTradeResponseParams
+		if params, ok = msg.Params.(SellTradeResponseParams); !ok {
+			return action, errors.New(InvalidMessageParams)
+		}
+		action.Type = acquire.SellTradeAction
+		action.Sell, action.Trade, err = parseSellTrade(params.CorporationsIndexes)
+	case UntieMergeResponseType:
+		var params UntieMergeResponseParams
+		if params, ok = msg.Params.(UntieMergeResponseParams); !ok {
+			return action, errors.New(InvalidMessageParams)
+		}
+		action.Type, action.Corporation = acquire.UntieMergeAction, params.CorporationIndex
+	case EndGameResponseType:
+		action.Type = acquire.ClaimEndGameAction
+	default:
+		return action, errors.New(UnknownMessageType)
+	}
+	return action, err
+}
+
+// Converts amounts indexed by string corporation indexes into amounts indexed by integer ones
+func parseIndexes(amounts map[string]int) (map[int]int, error) {
+	parsed := map[int]int{}
+	for key, amount := range amounts {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, errors.New(InvalidMessageParams)
+		}
+		parsed[index] = amount
+	}
+	return parsed, nil
+}
+
+// Splits sell and trade amounts indexed by string corporation indexes into two maps
+// indexed by integer ones
+func parseSellTrade(amounts map[string]SellTrade) (map[int]int, map[int]int, error) {
+	sell := map[int]int{}
+	trade := map[int]int{}
+	for key, amount := range amounts {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, nil, errors.New(InvalidMessageParams)
+		}
+		sell[index] = amount.Sell
+		trade[index] = amount.Trade
+	}
+	return sell, trade, nil
+}
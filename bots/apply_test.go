@@ -0,0 +1,75 @@
+package bots
+
+import (
+	"testing"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/player"
+)
+
+func TestApplyRandomBotMessages(t *testing.T) {
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 9})
+	bot := NewRandom(nil)
+
+	for i := 0; i < 30 && game.GameStateName() != interfaces.EndGameStateName; i++ {
+		st, _ := StatusFor(game, game.CurrentPlayerNumber())
+		bot.Update(st)
+		msg := bot.Play().(Message)
+		if err := Apply(game, game.CurrentPlayerNumber(), msg); err != nil {
+			t.Fatalf("Message %v must be applied, got error %s", msg, err)
+		}
+	}
+	if len(game.Log().Actions) == 0 {
+		t.Errorf("Applied messages must be recorded as game actions")
+	}
+}
+
+func TestApplyWrongMessages(t *testing.T) {
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 9})
+	current := game.CurrentPlayerNumber()
+
+	tests := []struct {
+		name     string
+		player   int
+		msg      Message
+		expected string
+	}{
+		{"unknown type", current, Message{Type: "fly"}, UnknownMessageType},
+		{"wrong params", current, Message{Type: PlayTileResponseType, Params: NewCorpResponseParams{}}, InvalidMessageParams},
+		{"malformed index", current, Message{Type: BuyResponseType, Params: BuyResponseParams{CorporationsIndexes: map[string]int{"a": 1}}}, InvalidMessageParams},
+		{"player not in turn", (current + 1) % 3, Message{Type: EndGameResponseType}, acquire.ActionPlayerMismatch},
+		{"action not allowed", current, Message{Type: NewCorpResponseType, Params: NewCorpResponseParams{CorporationIndex: 0}}, acquire.ActionNotAllowed},
+	}
+	for _, tt := range tests {
+		if err := Apply(game, tt.player, tt.msg); err == nil || err.Error() != tt.expected {
+			t.Errorf("%s: expected error %s, got %v", tt.name, tt.expected, err)
+		}
+	}
+}
+
+func TestApplyNegativeBuy(t *testing.T) {
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 9})
+	bot := NewRandom(nil)
+	for i := 0; i < 30 && game.GameStateName() != interfaces.BuyStockStateName; i++ {
+		st, _ := StatusFor(game, game.CurrentPlayerNumber())
+		bot.Update(st)
+		Apply(game, game.CurrentPlayerNumber(), bot.Play().(Message))
+	}
+	if game.GameStateName() != interfaces.BuyStockStateName {
+		t.Fatalf("Game must reach state %s", interfaces.BuyStockStateName)
+	}
+	current := game.CurrentPlayerNumber()
+	cash := players[current].Cash()
+
+	msg := Message{Type: BuyResponseType, Params: BuyResponseParams{CorporationsIndexes: map[string]int{"0": -3}}}
+	if err := Apply(game, current, msg); err == nil || err.Error() != acquire.InvalidSharesAmount {
+		t.Errorf("Negative buy amounts must fail with error %s, got %v", acquire.InvalidSharesAmount, err)
+	}
+	if players[current].Cash() != cash {
+		t.Errorf("Player cash must not change after a rejected buy, expected %d, got %d", cash, players[current].Cash())
+	}
+}
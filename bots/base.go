@@ -9,3 +9,50 @@ func (b *base) Update(st interface{}) {
 		b.status = st
 	}
 }
+
+// Returns true if the passed status meets the conditions to claim the end of the game
+func endConditionsReached(st Status) bool {
+	var active, safe int
+	for _, corp := range st.Corps {
+		if corp.Size >= endGameCorporationSize {
+			return true
+		}
+		if corp.Size > 0 {
+			active++
+		}
+		if corp.Size >= safeCorporationSize {
+			safe++
+		}
+	}
+	if active > 0 && active == safe {
+		return true
+	}
+	return false
+}
+
+// Returns what the bot knows about its rivals. In hidden assets games, in which their cash
+// and shares are secret, they are estimated: the shares which are neither in the bank nor
+// owned by the bot are split evenly among rivals, and rivals are assumed to have as much
+// cash as the bot.
+func rivalsInfo(st Status) []PlayerData {
+	if !st.HiddenAssets || len(st.RivalsInfo) == 0 {
+		return st.RivalsInfo
+	}
+	rivals := make([]PlayerData, len(st.RivalsInfo))
+	for i := range rivals {
+		rivals[i].Cash = st.PlayerInfo.Cash
+	}
+	for index, corp := range st.Corps {
+		owned := totalShares - corp.RemainingShares - st.PlayerInfo.OwnedShares[index]
+		if owned < 0 {
+			owned = 0
+		}
+		for i := range rivals {
+			rivals[i].OwnedShares[index] = owned / len(rivals)
+			if i < owned%len(rivals) {
+				rivals[i].OwnedShares[index]++
+			}
+		}
+	}
+	return rivals
+}
@@ -0,0 +1,115 @@
+package bots
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Difficulty levels
+const (
+	Easy   = "easy"
+	Medium = "medium"
+	Hard   = "hard"
+)
+
+const (
+	// UnknownDifficulty is an error message returned when trying to create a bot with an inexistent difficulty level.
+	UnknownDifficulty = "unknown_difficulty"
+)
+
+// Config holds the settings used to tune a bot. Nil settings are replaced by the
+// ones of the difficulty level, which is Medium if not set.
+// Not all bots use all settings: the random bot only uses Seed, and built-in bots ignore Options.
+type Config struct {
+	Difficulty string
+	// RiskAppetite, from 0 to 1, is the willingness to buy stock without a clear prospect of bonuses
+	RiskAppetite *float64
+	// CashReserve is the amount of cash the bot tries to keep when buying stock
+	CashReserve *int
+	// ClaimWillingness, from 0 to 1, is how eager the bot is to claim the end of the game.
+	// At 0.5 the bot claims it only when it is the richest player.
+	ClaimWillingness *float64
+	// MergeAggressiveness, from 0 to 1, is how much the bot values triggering mergers
+	MergeAggressiveness *float64
+	// Seed initialises the bot random generator. If zero, a seed based on the current time is used.
+	Seed int64
+	// Options holds settings specific to a bot, for bots registered by other packages
+	Options map[string]string
+	// mistakeRate is the probability of taking a random decision instead of the best one
+	mistakeRate float64
+}
+
+// Settings of a difficulty level
+type level struct {
+	riskAppetite        float64
+	cashReserve         int
+	claimWillingness    float64
+	mergeAggressiveness float64
+	mistakeRate         float64
+}
+
+// Settings of every difficulty level
+var difficulties = map[string]level{
+	Easy: {
+		riskAppetite:        0.8,
+		claimWillingness:    0.2,
+		mergeAggressiveness: 0.3,
+		mistakeRate:         0.3,
+	},
+	Medium: {
+		riskAppetite:        0.5,
+		claimWillingness:    0.5,
+		mergeAggressiveness: 0.5,
+	},
+	Hard: {
+		riskAppetite:        0.4,
+		cashReserve:         500,
+		claimWillingness:    0.6,
+		mergeAggressiveness: 0.7,
+	},
+}
+
+// Returns the passed config with its nil settings replaced by the ones of its difficulty level
+func (c Config) withDefaults() (Config, error) {
+	if c.Difficulty == "" {
+		c.Difficulty = Medium
+	}
+	preset, ok := difficulties[c.Difficulty]
+	if !ok {
+		return c, errors.New(UnknownDifficulty)
+	}
+	if c.RiskAppetite == nil {
+		c.RiskAppetite = &preset.riskAppetite
+	}
+	if c.CashReserve == nil {
+		c.CashReserve = &preset.cashReserve
+	}
+	if c.ClaimWillingness == nil {
+		c.ClaimWillingness = &preset.claimWillingness
+	}
+	if c.MergeAggressiveness == nil {
+		c.MergeAggressiveness = &preset.mergeAggressiveness
+	}
+	c.mistakeRate = preset.mistakeRate
+	return c, nil
+}
+
+// SeatSeed returns the seed for the bot playing at the passed seat of a game initialised
+// with the passed seed, so that replaying a game with the same seed makes its bots take
+// the same decisions
+func SeatSeed(gameSeed int64, seat int) int64 {
+	seed := int64(uint64(gameSeed)*0x9E3779B97F4A7C15 + uint64(seat) + 1)
+	if seed == 0 {
+		return 1
+	}
+	return seed
+}
+
+// Returns a random generator initialised with the config seed
+func (c Config) rand() *rand.Rand {
+	if c.Seed == 0 {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rand.New(rand.NewSource(c.Seed))
+}
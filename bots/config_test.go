@@ -0,0 +1,89 @@
+package bots
+
+import (
+	"testing"
+
+	"github.com/svera/acquire/interfaces"
+)
+
+func TestConfigDefaultsFromDifficulty(t *testing.T) {
+	risk := 0.9
+	cfg, err := Config{Difficulty: Hard, RiskAppetite: &risk}.withDefaults()
+	if err != nil {
+		t.Fatalf("Hard difficulty must be accepted, got error %s", err)
+	}
+	if *cfg.RiskAppetite != 0.9 {
+		t.Errorf("Set values must be kept, expected risk appetite %f, got %f", 0.9, *cfg.RiskAppetite)
+	}
+	if *cfg.CashReserve != difficulties[Hard].cashReserve {
+		t.Errorf("Unset values must be taken from the difficulty level, expected cash reserve %d, got %d", difficulties[Hard].cashReserve, *cfg.CashReserve)
+	}
+
+	if cfg, _ = (Config{}).withDefaults(); cfg.Difficulty != Medium {
+		t.Errorf("Difficulty must default to %s, got %s", Medium, cfg.Difficulty)
+	}
+}
+
+func TestConfigZeroSettingsAreKept(t *testing.T) {
+	var zeroFloat float64
+	var zeroInt int
+	cfg, _ := Config{
+		Difficulty:          Hard,
+		RiskAppetite:        &zeroFloat,
+		CashReserve:         &zeroInt,
+		ClaimWillingness:    &zeroFloat,
+		MergeAggressiveness: &zeroFloat,
+	}.withDefaults()
+	if *cfg.RiskAppetite != 0 {
+		t.Errorf("Risk appetite set to 0 must be kept, got %f", *cfg.RiskAppetite)
+	}
+	if *cfg.CashReserve != 0 {
+		t.Errorf("Cash reserve set to 0 must be kept, got %d", *cfg.CashReserve)
+	}
+	if *cfg.ClaimWillingness != 0 {
+		t.Errorf("Claim willingness set to 0 must be kept, got %f", *cfg.ClaimWillingness)
+	}
+	if *cfg.MergeAggressiveness != 0 {
+		t.Errorf("Merge aggressiveness set to 0 must be kept, got %f", *cfg.MergeAggressiveness)
+	}
+}
+
+func TestCreateUnknownDifficulty(t *testing.T) {
+	if _, err := Create("greedy", Config{Difficulty: "nightmare"}); err == nil || err.Error() != UnknownDifficulty {
+		t.Errorf("Create must return error %s when the difficulty level does not exist", UnknownDifficulty)
+	}
+}
+
+func TestGreedyKeepsCashReserve(t *testing.T) {
+	reserve := 5400
+	bot := NewGreedy(Config{CashReserve: &reserve})
+	st := emptyStatus(interfaces.BuyStockStateName)
+	st.Corps[2] = CorpData{Size: 5, MajorityBonus: 5000, MinorityBonus: 2500, Price: 500, RemainingShares: 20}
+	st.RivalsInfo[0].OwnedShares[2] = 2
+	bot.Update(st)
+
+	msg := bot.Play().(Message)
+	if buys := msg.Params.(BuyResponseParams).CorporationsIndexes; buys["2"] != 1 {
+		t.Errorf("Greedy bot must not spend its cash reserve, expected to buy 1 share, got %v", buys)
+	}
+}
+
+func TestGreedyClaimWillingness(t *testing.T) {
+	st := emptyStatus(interfaces.PlayTileStateName)
+	st.Corps[0] = CorpData{Size: endGameCorporationSize, Price: 1000}
+	st.Hand = map[string]bool{"1A": true}
+	st.PlayerInfo.Cash = 6200
+
+	eagerness, reluctance := 1.0, 0.1
+	eager := NewGreedy(Config{ClaimWillingness: &eagerness})
+	eager.Update(st)
+	if msg := eager.Play().(Message); msg.Type != EndGameResponseType {
+		t.Errorf("Eager greedy bot must claim the end of the game when it is the richest player, got %v", msg)
+	}
+
+	reluctant := NewGreedy(Config{ClaimWillingness: &reluctance})
+	reluctant.Update(st)
+	if msg := reluctant.Play().(Message); msg.Type == EndGameResponseType {
+		t.Errorf("Reluctant greedy bot must not claim the end of the game with a small lead")
+	}
+}
@@ -0,0 +1,221 @@
+package bots
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/svera/acquire/interfaces"
+)
+
+const (
+	// ExternalTimeout is an error message returned when an out-of-process bot does not answer in time
+	ExternalTimeout = "external_timeout"
+	// ExternalExited is an error message returned when an out-of-process bot exits before answering
+	ExternalExited = "external_exited"
+	// ExternalBotError is an error message returned when an out-of-process bot reports an error
+	ExternalBotError = "external_bot_error"
+	// InvalidExternalResponse is an error message returned when an out-of-process bot answers
+	// with something which is not a valid message
+	InvalidExternalResponse = "invalid_external_response"
+	// MissingCommand is an error message returned when creating an out-of-process bot without command
+	MissingCommand = "missing_command"
+
+	// DefaultExternalTimeout is the time an out-of-process bot has to answer if none is configured
+	DefaultExternalTimeout = 5 * time.Second
+	// Maximum length of a protocol line
+	maxLineLength = 1024 * 1024
+)
+
+// Request is the line sent to out-of-process bots when they have to move.
+// Out-of-process bots talk to the game through a line-delimited JSON protocol over
+// their standard input and output. Every time the bot has to move, it receives
+// a line with a Request, holding the game status as seen by the bot, and must
+// answer with a line holding a Message, for example:
+//
+//	-> {"Status":{"Board":{...},"State":"PlayTile","Hand":{"5E":true},...}}
+//	<- {"Type":"playTile","Params":{"Tile":"5E"}}
+//
+// A bot which cannot decide a move can report it with an error message, such as
+// {"Type":"error","Params":"description"}. Anything written to its standard error is
+// not part of the protocol. The bot process must exit when its standard input is closed.
+type Request struct {
+	Status Status
+}
+
+// ExternalParams holds the settings of an out-of-process bot
+type ExternalParams struct {
+	// Command is the path of the bot executable
+	Command string
+	Args    []string
+	// Timeout is the time the bot has to answer every request. If zero, DefaultExternalTimeout is used.
+	Timeout time.Duration
+	// Stderr receives what the bot writes to its standard error. If nil, it is discarded.
+	Stderr io.Writer
+}
+
+// External is a struct which implements interfaces.Bot by running a bot as a subprocess,
+// which is asked for its moves through the line-delimited JSON protocol.
+// Once the bot fails, Play returns empty messages and Err returns the cause.
+type External struct {
+	*base
+	params ExternalParams
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan []byte
+	err    error
+}
+
+// NewExternal starts the bot executable and returns its adapter
+func NewExternal(params ExternalParams) (*External, error) {
+	if params.Command == "" {
+		return nil, errors.New(MissingCommand)
+	}
+	if params.Timeout == 0 {
+		params.Timeout = DefaultExternalTimeout
+	}
+	cmd := exec.Command(params.Command, params.Args...)
+	cmd.Stderr = params.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	e := &External{
+		base:   &base{},
+		params: params,
+		cmd:    cmd,
+		stdin:  stdin,
+		lines:  make(chan []byte),
+	}
+	go e.read(stdout)
+	return e, nil
+}
+
+// Reads the lines written by the bot until its output is closed
+func (e *External) read(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	for scanner.Scan() {
+		e.lines <- append([]byte{}, scanner.Bytes()...)
+	}
+	close(e.lines)
+}
+
+// Play sends the last status received to the bot and returns its answer
+func (e *External) Play() interface{} {
+	if e.err != nil {
+		return Message{}
+	}
+	msg, err := e.request()
+	if err != nil {
+		e.err = err
+		e.cmd.Process.Kill()
+		return Message{}
+	}
+	return msg
+}
+
+func (e *External) request() (Message, error) {
+	var msg Message
+	req, err := json.Marshal(Request{Status: e.status})
+	if err != nil {
+		return msg, err
+	}
+	if _, err = e.stdin.Write(append(req, '\n')); err != nil {
+		return msg, errors.New(ExternalExited)
+	}
+	select {
+	case line, ok := <-e.lines:
+		if !ok {
+			return msg, errors.New(ExternalExited)
+		}
+		if err = json.Unmarshal(line, &msg); err != nil {
+			return msg, errors.New(InvalidExternalResponse)
+		}
+		if msg.Type == ErrorResponseType {
+			return msg, fmt.Errorf("%s: %v", ExternalBotError, msg.Params)
+		}
+		return msg, nil
+	case <-time.After(e.params.Timeout):
+		return msg, errors.New(ExternalTimeout)
+	}
+}
+
+// Err returns the error which made the bot fail, if any
+func (e *External) Err() error {
+	return e.err
+}
+
+// Close closes the bot standard input and waits for it to exit, killing it if
+// it does not do it in time
+func (e *External) Close() error {
+	e.stdin.Close()
+	done := make(chan error, 1)
+	go func() {
+		for range e.lines {
+		}
+		done <- e.cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		if e.err != nil {
+			return nil
+		}
+		return err
+	case <-time.After(e.params.Timeout):
+		e.cmd.Process.Kill()
+		return errors.New(ExternalTimeout)
+	}
+}
+
+// Serve runs the passed bot as an out-of-process one, answering the requests read
+// from r with messages written to w until r is closed
+func Serve(r io.Reader, w io.Writer, bot interfaces.Bot) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		var req Request
+		var msg interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			msg = Message{Type: ErrorResponseType, Params: err.Error()}
+		} else {
+			bot.Update(req.Status)
+			msg = bot.Play()
+		}
+		if err := enc.Encode(msg); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Creates an out-of-process bot from the options of the passed config:
+// "command" holds the executable and its arguments separated by spaces and
+// "timeout" the time to answer, in the format accepted by time.ParseDuration
+func newExternalFromConfig(cfg Config) (interfaces.Bot, error) {
+	fields := strings.Fields(cfg.Options["command"])
+	if len(fields) == 0 {
+		return nil, errors.New(MissingCommand)
+	}
+	params := ExternalParams{Command: fields[0], Args: fields[1:]}
+	if timeout, ok := cfg.Options["timeout"]; ok {
+		var err error
+		if params.Timeout, err = time.ParseDuration(timeout); err != nil {
+			return nil, err
+		}
+	}
+	return NewExternal(params)
+}
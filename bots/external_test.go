@@ -0,0 +1,127 @@
+package bots
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/player"
+)
+
+// Name of the environment variable which makes the test binary behave as an out-of-process bot
+const externalHelperVar = "ACQUIRE_EXTERNAL_BOT_HELPER"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(externalHelperVar) {
+	case "":
+		os.Exit(m.Run())
+	case "random":
+		Serve(os.Stdin, os.Stdout, NewRandom(rand.New(rand.NewSource(1))))
+	case "silent":
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+		}
+	case "failing":
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			os.Stdout.WriteString(`{"Type":"error","Params":"out of ideas"}` + "\n")
+		}
+	}
+	os.Exit(0)
+}
+
+func newExternalHelper(t *testing.T, behaviour string) *External {
+	os.Setenv(externalHelperVar, behaviour)
+	defer os.Unsetenv(externalHelperVar)
+	bot, err := NewExternal(ExternalParams{Command: os.Args[0], Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("External bot must start, got error %s", err)
+	}
+	return bot
+}
+
+func TestExternalPlaysFullGame(t *testing.T) {
+	external := newExternalHelper(t, "random")
+	defer external.Close()
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 2})
+	seats := []interfaces.Bot{external, NewGreedy(Config{}), NewGreedy(Config{})}
+
+	for i := 0; i < 1000 && game.GameStateName() != interfaces.EndGameStateName; i++ {
+		number := game.CurrentPlayerNumber()
+		st, _ := StatusFor(game, number)
+		seats[number].Update(st)
+		msg := seats[number].Play().(Message)
+		if external.Err() != nil {
+			t.Fatalf("External bot must answer, got error %s", external.Err())
+		}
+		if err := Apply(game, number, msg); err != nil {
+			t.Fatalf("Message %v of player %d must be applied, got error %s", msg, number, err)
+		}
+	}
+	if game.GameStateName() != interfaces.EndGameStateName {
+		t.Errorf("Game with an external bot must end")
+	}
+}
+
+func TestExternalTimeout(t *testing.T) {
+	os.Setenv(externalHelperVar, "silent")
+	bot, _ := NewExternal(ExternalParams{Command: os.Args[0], Timeout: 50 * time.Millisecond})
+	os.Unsetenv(externalHelperVar)
+	defer bot.Close()
+	bot.Update(emptyStatus(interfaces.PlayTileStateName))
+
+	if msg := bot.Play().(Message); msg.Type != "" {
+		t.Errorf("External bot which does not answer in time must return an empty message, got %v", msg)
+	}
+	if bot.Err() == nil || bot.Err().Error() != ExternalTimeout {
+		t.Errorf("External bot which does not answer in time must fail with error %s, got %v", ExternalTimeout, bot.Err())
+	}
+}
+
+func TestExternalReportsError(t *testing.T) {
+	bot := newExternalHelper(t, "failing")
+	defer bot.Close()
+	bot.Update(emptyStatus(interfaces.PlayTileStateName))
+	bot.Play()
+
+	if bot.Err() == nil || !strings.HasPrefix(bot.Err().Error(), ExternalBotError) || !strings.Contains(bot.Err().Error(), "out of ideas") {
+		t.Errorf("External bot reporting an error must fail with error %s and its description, got %v", ExternalBotError, bot.Err())
+	}
+}
+
+func TestCreateExternalWithoutCommand(t *testing.T) {
+	if _, err := Create("external", Config{}); err == nil || err.Error() != MissingCommand {
+		t.Errorf("Creating an external bot without command must return error %s", MissingCommand)
+	}
+}
+
+func TestMessageJSONRoundTrip(t *testing.T) {
+	messages := []Message{
+		{Type: PlayTileResponseType, Params: PlayTileResponseParams{Tile: "5E"}},
+		{Type: BuyResponseType, Params: BuyResponseParams{CorporationsIndexes: map[string]int{"2": 3}}},
+		{Type: SellTradeResponseType, Params: SellTradeResponseParams{CorporationsIndexes: map[string]SellTrade{"0": {Sell: 1, Trade: 2}}}},
+		{Type: EndGameResponseType},
+	}
+	for _, msg := range messages {
+		data, _ := json.Marshal(msg)
+		var decoded Message
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Message %s must be decoded, got error %s", data, err)
+		}
+		if _, err := toAction(0, decoded); err != nil {
+			t.Errorf("Decoded message %s must be applicable, got error %s", data, err)
+		}
+	}
+
+	var decoded Message
+	if err := json.Unmarshal([]byte(`{"Type":"fly"}`), &decoded); err == nil || err.Error() != UnknownMessageType {
+		t.Errorf("Decoding a message of unknown type must return error %s", UnknownMessageType)
+	}
+}
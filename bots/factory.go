@@ -2,6 +2,8 @@ package bots
 
 import (
 	"errors"
+	"sort"
+	"sync"
 
 	"github.com/svera/acquire/interfaces"
 )
@@ -9,14 +11,94 @@ import (
 const (
 	// BotNotFound is an error message returned when trying to instance un inexistent bot.
 	BotNotFound = "bot_not_found"
+	// PlayerNotFound is an error message returned when asking for the status of an inexistent player.
+	PlayerNotFound = "player_not_found"
+	// BotAlreadyRegistered is an error message returned when registering a bot under a name already taken.
+	BotAlreadyRegistered = "bot_already_registered"
+	// InvalidRegistration is an error message returned when registering a bot without name or constructor.
+	InvalidRegistration = "invalid_registration"
 )
 
-// Create returns a new instance of a bot.
-func Create(name string) (interfaces.Bot, error) {
-	switch name {
-	case "random":
-		return NewRandom(), nil
-	default:
+// Constructor returns a new instance of a bot tuned with the passed config,
+// whose nil settings have already been replaced by the ones of its difficulty level.
+type Constructor func(cfg Config) (interfaces.Bot, error)
+
+// Info describes a registered bot
+type Info struct {
+	Name        string
+	Description string
+}
+
+type registration struct {
+	description string
+	constructor Constructor
+}
+
+var (
+	registryMutex sync.RWMutex
+	registry      = map[string]registration{}
+)
+
+// Search iterations of the MCTS bot for every difficulty level
+var mctsIterations = map[string]int{
+	Easy:   25,
+	Medium: DefaultMCTSIterations,
+	Hard:   300,
+}
+
+func init() {
+	Register("random", "Plays random moves", func(cfg Config) (interfaces.Bot, error) {
+		return NewRandom(cfg.rand()), nil
+	})
+	Register("greedy", "Rule based bot which plays for the most immediate value", func(cfg Config) (interfaces.Bot, error) {
+		return NewGreedy(cfg), nil
+	})
+	Register("mcts", "Monte Carlo tree search bot", func(cfg Config) (interfaces.Bot, error) {
+		return NewMCTS(MCTSParams{Iterations: mctsIterations[cfg.Difficulty], Personality: cfg}), nil
+	})
+	Register("external", "Bot running as a subprocess, set up with the command and timeout options", newExternalFromConfig)
+}
+
+// Register makes a bot available under the passed name, so it can be instanced with Create.
+// It is safe to call it from other packages' init functions.
+func Register(name string, description string, constructor Constructor) error {
+	if name == "" || constructor == nil {
+		return errors.New(InvalidRegistration)
+	}
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if _, exist := registry[name]; exist {
+		return errors.New(BotAlreadyRegistered)
+	}
+	registry[name] = registration{description: description, constructor: constructor}
+	return nil
+}
+
+// Available returns the registered bots, sorted by name
+func Available() []Info {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	available := make([]Info, 0, len(registry))
+	for name, reg := range registry {
+		available = append(available, Info{Name: name, Description: reg.description})
+	}
+	sort.Slice(available, func(i, j int) bool {
+		return available[i].Name < available[j].Name
+	})
+	return available
+}
+
+// Create returns a new instance of the bot registered under the passed name, tuned with the passed config.
+func Create(name string, cfg Config) (interfaces.Bot, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	registryMutex.RLock()
+	reg, exist := registry[name]
+	registryMutex.RUnlock()
+	if !exist {
 		return nil, errors.New(BotNotFound)
 	}
+	return reg.constructor(cfg)
 }
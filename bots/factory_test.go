@@ -0,0 +1,54 @@
+package bots
+
+import (
+	"testing"
+
+	"github.com/svera/acquire/interfaces"
+)
+
+func TestRegisterBot(t *testing.T) {
+	var received Config
+	constructor := func(cfg Config) (interfaces.Bot, error) {
+		received = cfg
+		return NewRandom(nil), nil
+	}
+	if err := Register("testBot", "Bot used in tests", constructor); err != nil {
+		t.Fatalf("Bot must be registered, got error %s", err)
+	}
+	if err := Register("testBot", "Bot used in tests", constructor); err == nil || err.Error() != BotAlreadyRegistered {
+		t.Errorf("Registering a bot twice must return error %s", BotAlreadyRegistered)
+	}
+	if err := Register("", "Bot used in tests", constructor); err == nil || err.Error() != InvalidRegistration {
+		t.Errorf("Registering a bot without name must return error %s", InvalidRegistration)
+	}
+
+	bot, err := Create("testBot", Config{Options: map[string]string{"style": "bold"}})
+	if err != nil || bot == nil {
+		t.Fatalf("Registered bot must be created by name")
+	}
+	if received.Options["style"] != "bold" || received.Difficulty != Medium {
+		t.Errorf("Constructor must receive the passed config with its defaults, got %v", received)
+	}
+
+	found := false
+	for _, info := range Available() {
+		if info.Name == "testBot" && info.Description == "Bot used in tests" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Registered bot must be listed as available")
+	}
+}
+
+func TestAvailableIncludesBuiltInBots(t *testing.T) {
+	names := map[string]bool{}
+	for _, info := range Available() {
+		names[info.Name] = true
+	}
+	for _, name := range []string{"random", "greedy", "mcts"} {
+		if !names[name] {
+			t.Errorf("Built-in bot %s must be available", name)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package bots
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/tile"
+)
+
+const (
+	maxBuyableShares = 3
+	// Value given to the stock share received when founding a corporation,
+	// on top of its prospects
+	foundingValue = 1000
+	// Maximum price of a defunct corporation share the bot holds instead of selling,
+	// hoping the corporation is founded again
+	maxHeldSharePrice = 400
+	// Maximum number of corporations on board, besides the defunct one, for it to
+	// be likely founded again before the game ends
+	maxCorporationsToRefound = 4
+)
+
+// Greedy is a struct which implements a rule based AI. It plays the tile which brings
+// it the most immediate value, buys stock to get or defend majority and minority
+// positions and, in mergers, sells or trades its defunct shares depending on which
+// one is worth more. Easy greedy bots sometimes place a random tile.
+type Greedy struct {
+	*base
+	cfg Config
+	rn  *rand.Rand
+}
+
+// NewGreedy returns a new instance of the greedy AI bot tuned with the passed config.
+// An unknown difficulty level is handled as Medium.
+func NewGreedy(cfg Config) *Greedy {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		cfg.Difficulty = Medium
+		cfg, _ = cfg.withDefaults()
+	}
+	return &Greedy{
+		&base{},
+		cfg,
+		cfg.rand(),
+	}
+}
+
+// Play analyses the current game status and returns a message with the
+// next play movement by the bot AI
+func (g *Greedy) Play() interface{} {
+	var msg Message
+
+	if !g.status.IsLastRound && g.claimEndGame() {
+		msg = Message{
+			Type: EndGameResponseType,
+		}
+	} else {
+		switch g.status.State {
+		case interfaces.PlayTileStateName:
+			msg = Message{
+				Type:   PlayTileResponseType,
+				Params: g.playTile(),
+			}
+		case interfaces.FoundCorpStateName:
+			msg = Message{
+				Type:   NewCorpResponseType,
+				Params: g.foundCorporation(),
+			}
+		case interfaces.BuyStockStateName:
+			msg = Message{
+				Type:   BuyResponseType,
+				Params: g.buyStock(),
+			}
+		case interfaces.SellTradeStateName:
+			msg = Message{
+				Type:   SellTradeResponseType,
+				Params: g.sellTrade(),
+			}
+		case interfaces.UntieMergeStateName:
+			msg = Message{
+				Type:   UntieMergeResponseType,
+				Params: g.untieMerge(),
+			}
+		}
+	}
+
+	return msg
+}
+
+// Plays the playable tile with the highest value, the first one in coordinates
+// order in case of a tie
+func (g *Greedy) playTile() PlayTileResponseParams {
+	coords := []string{}
+	for k, playable := range g.status.Hand {
+		if playable {
+			coords = append(coords, k)
+		}
+	}
+	sort.Strings(coords)
+	if len(coords) > 0 && g.rn.Float64() < g.cfg.mistakeRate {
+		return PlayTileResponseParams{Tile: coords[g.rn.Intn(len(coords))]}
+	}
+	best, bestValue := "", 0
+	for _, c := range coords {
+		if value := g.tileValue(c); best == "" || value > bestValue {
+			best, bestValue = c, value
+		}
+	}
+	return PlayTileResponseParams{Tile: best}
+}
+
+// Estimates how much placing the tile with the passed coordinates is worth to the bot
+func (g *Greedy) tileValue(coords string) int {
+	corps, unincorporated := g.adjacentOwners(coords)
+	switch {
+	case len(corps) > 1:
+		value := g.mergeValue(corps)
+		if value > 0 {
+			value = int(float64(value) * *g.cfg.MergeAggressiveness * 2)
+		}
+		return value
+	case len(corps) == 1:
+		// Each tile added raises the stock price about 100$ until the corporation gets big
+		return g.status.PlayerInfo.OwnedShares[corps[0]] * 100
+	case unincorporated > 0 && g.inactiveCorporation() != -1:
+		return foundingValue
+	}
+	return 0
+}
+
+// Estimates the bonuses the bot would get in a merge of the passed corporations,
+// minus the ones rivals would get. The largest corporation is supposed to be the acquirer.
+func (g *Greedy) mergeValue(corps []int) int {
+	sort.SliceStable(corps, func(i, j int) bool {
+		return g.status.Corps[corps[i]].Size > g.status.Corps[corps[j]].Size
+	})
+	value := 0
+	for _, defunct := range corps[1:] {
+		corp := g.status.Corps[defunct]
+		switch g.position(defunct) {
+		case 1:
+			value += corp.MajorityBonus
+		case 2:
+			value += corp.MinorityBonus
+		default:
+			value -= corp.MinorityBonus
+		}
+	}
+	return value
+}
+
+// Returns the indexes of the corporations adjacent to the passed coordinates
+// and the number of adjacent unincorporated tiles
+func (g *Greedy) adjacentOwners(coords string) ([]int, int) {
+	corps := []int{}
+	unincorporated := 0
+	for _, adjacent := range adjacentCoords(coords) {
+		owner := g.status.Board[adjacent]
+		if owner == interfaces.UnincorporatedOwner {
+			unincorporated++
+			continue
+		}
+		if index, err := strconv.Atoi(owner); err == nil && !containsIndex(corps, index) {
+			corps = append(corps, index)
+		}
+	}
+	return corps, unincorporated
+}
+
+// Founds the most expensive corporation available, as its founder share is worth more
+func (g *Greedy) foundCorporation() NewCorpResponseParams {
+	return NewCorpResponseParams{CorporationIndex: g.inactiveCorporation()}
+}
+
+// Returns the index of the most expensive corporation not on board, or -1 if all are active
+func (g *Greedy) inactiveCorporation() int {
+	for i := len(g.status.Corps) - 1; i >= 0; i-- {
+		if g.status.Corps[i].Size == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Buys shares one by one, each time from the corporation where a share
+// improves the most the bot's chances of getting a bonus, keeping the cash reserve
+func (g *Greedy) buyStock() BuyResponseParams {
+	buys := map[string]int{}
+	cash := g.status.PlayerInfo.Cash - *g.cfg.CashReserve
+	owned := g.status.PlayerInfo.OwnedShares
+	for i := 0; i < maxBuyableShares; i++ {
+		best, bestValue := -1, 0
+		for index, corp := range g.status.Corps {
+			bought := buys[strconv.Itoa(index)]
+			if corp.Size == 0 || corp.RemainingShares <= bought || corp.Price > cash {
+				continue
+			}
+			if value := g.shareValue(index, owned[index]); value > bestValue {
+				best, bestValue = index, value
+			}
+		}
+		if best == -1 {
+			break
+		}
+		buys[strconv.Itoa(best)]++
+		owned[best]++
+		cash -= g.status.Corps[best].Price
+	}
+	return BuyResponseParams{CorporationsIndexes: buys}
+}
+
+// Estimates the value, per dollar spent, of buying one more share of the passed
+// corporation when owning the passed amount of them
+func (g *Greedy) shareValue(index int, owned int) int {
+	corp := g.status.Corps[index]
+	first, second := g.rivalsTopShares(index)
+	var bonus int
+	switch {
+	case owned <= first && first-owned < maxBuyableShares:
+		// Contest the majority
+		bonus = corp.MajorityBonus
+	case owned > first && owned-first <= 1:
+		// Defend the majority
+		bonus = corp.MajorityBonus / 2
+	case owned <= second && second-owned < maxBuyableShares:
+		// Contest the minority
+		bonus = corp.MinorityBonus
+	case corp.Size < safeCorporationSize:
+		// Shares of small corporations are expected to raise their price
+		bonus = int(float64(corp.Price) * *g.cfg.RiskAppetite)
+	}
+	return bonus * 100 / corp.Price
+}
+
+// Sells or trades shares of the corporation being defunct, depending on which
+// is worth more: a share of the defunct corporation or half a share of the acquirer.
+// Instead of selling cheap shares which are not traded, it holds them if the
+// corporation is likely to be founded again, as they recover their value then.
+func (g *Greedy) sellTrade() SellTradeResponseParams {
+	operations := map[string]SellTrade{}
+	for index, corp := range g.status.Corps {
+		owned := g.status.PlayerInfo.OwnedShares[index]
+		if !corp.Defunct || owned == 0 {
+			continue
+		}
+		operation := SellTrade{Sell: owned}
+		if g.status.Acquirer != -1 {
+			acquirer := g.status.Corps[g.status.Acquirer]
+			if acquirer.Price > corp.Price*2 {
+				operation.Trade = owned / 2 * 2
+				if operation.Trade > acquirer.RemainingShares*2 {
+					operation.Trade = acquirer.RemainingShares * 2
+				}
+				operation.Sell = owned - operation.Trade
+			}
+		}
+		if operation.Trade == 0 && corp.Price <= maxHeldSharePrice && g.isLikelyRefounded(index) {
+			operation.Sell = 0
+		}
+		operations[strconv.Itoa(index)] = operation
+	}
+	return SellTradeResponseParams{CorporationsIndexes: operations}
+}
+
+// Returns true if the defunct corporation with the passed index is likely to be founded
+// again before the game ends, as there is room on board for new corporations
+func (g *Greedy) isLikelyRefounded(defunct int) bool {
+	if g.status.IsLastRound || endConditionsReached(g.status) {
+		return false
+	}
+	onBoard := 0
+	for index, corp := range g.status.Corps {
+		if index != defunct && corp.Size > 0 {
+			onBoard++
+		}
+	}
+	return onBoard <= maxCorporationsToRefound
+}
+
+// Chooses the tied corporation in which the bot has more shares, to keep its stock
+// if it is the acquirer or to get paid its bonuses first if it is a defunct one
+func (g *Greedy) untieMerge() UntieMergeResponseParams {
+	best := g.status.TiedCorps[0]
+	for _, index := range g.status.TiedCorps {
+		if g.status.PlayerInfo.OwnedShares[index] > g.status.PlayerInfo.OwnedShares[best] {
+			best = index
+		}
+	}
+	return UntieMergeResponseParams{CorporationIndex: best}
+}
+
+// Claims the end of the game if the bot's net worth is high enough compared with
+// the richest rival. The more willing to claim, the less margin is required.
+func (g *Greedy) claimEndGame() bool {
+	if !endConditionsReached(g.status) {
+		return false
+	}
+	margin := 1 + (0.5-*g.cfg.ClaimWillingness)*0.2
+	own := float64(g.netWorth(g.status.PlayerInfo))
+	for _, rival := range rivalsInfo(g.status) {
+		if float64(g.netWorth(rival))*margin >= own {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the cash of the passed player plus the value of its shares at current prices
+func (g *Greedy) netWorth(pl PlayerData) int {
+	worth := pl.Cash
+	for index, corp := range g.status.Corps {
+		worth += pl.OwnedShares[index] * corp.Price
+	}
+	return worth
+}
+
+// Returns the position (1 for majority, 2 for minority) of the bot among the stockholders
+// of the passed corporation, or 0 if it gets no bonus
+func (g *Greedy) position(index int) int {
+	owned := g.status.PlayerInfo.OwnedShares[index]
+	if owned == 0 {
+		return 0
+	}
+	first, second := g.rivalsTopShares(index)
+	switch {
+	case owned >= first:
+		return 1
+	case owned >= second:
+		return 2
+	}
+	return 0
+}
+
+// Returns the two highest amounts of shares of the passed corporation owned by rivals
+func (g *Greedy) rivalsTopShares(index int) (int, int) {
+	var first, second int
+	for _, rival := range rivalsInfo(g.status) {
+		shares := rival.OwnedShares[index]
+		if shares > first {
+			first, second = shares, first
+		} else if shares > second {
+			second = shares
+		}
+	}
+	return first, second
+}
+
+// Returns the coordinates of the cells next to the passed ones
+func adjacentCoords(coords string) []string {
+	tl, err := tile.Parse(coords)
+	if err != nil {
+		return nil
+	}
+	adjacent := []string{}
+	for i, letter := range tile.Letters {
+		if letter != tl.Letter() {
+			continue
+		}
+		if tl.Number() > 1 {
+			adjacent = append(adjacent, tile.Coords(tile.New(tl.Number()-1, letter)))
+		}
+		if tl.Number() < 12 {
+			adjacent = append(adjacent, tile.Coords(tile.New(tl.Number()+1, letter)))
+		}
+		if i > 0 {
+			adjacent = append(adjacent, tile.Coords(tile.New(tl.Number(), tile.Letters[i-1])))
+		}
+		if i < len(tile.Letters)-1 {
+			adjacent = append(adjacent, tile.Coords(tile.New(tl.Number(), tile.Letters[i+1])))
+		}
+	}
+	return adjacent
+}
+
+func containsIndex(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
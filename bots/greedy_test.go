@@ -0,0 +1,106 @@
+package bots
+
+import (
+	"testing"
+
+	"github.com/svera/acquire/interfaces"
+)
+
+func TestGreedyPlaysMergeTileWhereItHasMajority(t *testing.T) {
+	bot := NewGreedy(Config{})
+	st := emptyStatus(interfaces.PlayTileStateName)
+	st.Board["4E"], st.Board["6E"] = "0", "1"
+	st.Corps[0] = CorpData{Size: 2, MajorityBonus: 2000, MinorityBonus: 1000, Price: 200}
+	st.Corps[1] = CorpData{Size: 3, MajorityBonus: 3000, MinorityBonus: 1500, Price: 300}
+	st.PlayerInfo.OwnedShares[0] = 3
+	st.Hand = map[string]bool{"1A": true, "5E": true, "9I": true}
+	bot.Update(st)
+
+	msg := bot.Play().(Message)
+	if msg.Type != PlayTileResponseType || msg.Params.(PlayTileResponseParams).Tile != "5E" {
+		t.Errorf("Greedy bot must play the tile which merges a corporation it has majority in, got %v", msg)
+	}
+}
+
+func TestGreedyBuysToContestMajority(t *testing.T) {
+	bot := NewGreedy(Config{})
+	st := emptyStatus(interfaces.BuyStockStateName)
+	st.Corps[2] = CorpData{Size: 5, MajorityBonus: 5000, MinorityBonus: 2500, Price: 500, RemainingShares: 20}
+	st.Corps[3] = CorpData{Size: 5, MajorityBonus: 5000, MinorityBonus: 2500, Price: 500, RemainingShares: 20}
+	st.RivalsInfo[0].OwnedShares[2] = 2
+	st.RivalsInfo[0].OwnedShares[3] = 8
+	bot.Update(st)
+
+	msg := bot.Play().(Message)
+	if buys := msg.Params.(BuyResponseParams).CorporationsIndexes; buys["2"] != 3 {
+		t.Errorf("Greedy bot must buy shares of the corporation whose majority can be taken, got %v", buys)
+	}
+}
+
+func TestGreedyTradesWhenAcquirerIsWorthMore(t *testing.T) {
+	bot := NewGreedy(Config{})
+	st := emptyStatus(interfaces.SellTradeStateName)
+	st.Corps[0] = CorpData{Size: 2, Price: 200, Defunct: true}
+	st.Corps[6] = CorpData{Size: 20, Price: 1000, RemainingShares: 1}
+	st.Acquirer = 6
+	st.PlayerInfo.OwnedShares[0] = 5
+	bot.Update(st)
+
+	msg := bot.Play().(Message)
+	if op := msg.Params.(SellTradeResponseParams).CorporationsIndexes["0"]; op.Trade != 2 || op.Sell != 3 {
+		t.Errorf("Greedy bot must trade as many shares as the acquirer stock allows and sell the rest, got %v", op)
+	}
+}
+
+func TestGreedyHoldsWhenDefunctIsLikelyRefounded(t *testing.T) {
+	bot := NewGreedy(Config{})
+	st := emptyStatus(interfaces.SellTradeStateName)
+	st.Corps[0] = CorpData{Size: 3, Price: 300, Defunct: true}
+	st.Corps[1] = CorpData{Size: 6, Price: 500, RemainingShares: 20}
+	st.Acquirer = 1
+	st.PlayerInfo.OwnedShares[0] = 4
+	bot.Update(st)
+
+	msg := bot.Play().(Message)
+	if op := msg.Params.(SellTradeResponseParams).CorporationsIndexes["0"]; op.Trade != 0 || op.Sell != 0 {
+		t.Errorf("Greedy bot must hold shares of a cheap defunct corporation likely to be founded again, got %v", op)
+	}
+
+	// With most corporations on board, the defunct one is unlikely to come back
+	for index := 2; index < 7; index++ {
+		st.Corps[index] = CorpData{Size: 3, Price: 300, RemainingShares: 25}
+	}
+	bot.Update(st)
+	msg = bot.Play().(Message)
+	if op := msg.Params.(SellTradeResponseParams).CorporationsIndexes["0"]; op.Sell != 4 {
+		t.Errorf("Greedy bot must sell shares of a defunct corporation unlikely to be founded again, got %v", op)
+	}
+
+	st.IsLastRound = true
+	for index := 2; index < 7; index++ {
+		st.Corps[index] = CorpData{}
+	}
+	bot.Update(st)
+	msg = bot.Play().(Message)
+	if op := msg.Params.(SellTradeResponseParams).CorporationsIndexes["0"]; op.Sell != 4 {
+		t.Errorf("Greedy bot must sell shares of a defunct corporation in the last round, got %v", op)
+	}
+}
+
+func TestCreateGreedy(t *testing.T) {
+	if bot, err := Create("greedy", Config{}); err != nil || bot == nil {
+		t.Errorf("Greedy bot must be created by name")
+	}
+}
+
+func emptyStatus(state string) Status {
+	st := Status{
+		Board:      map[string]string{},
+		State:      state,
+		Hand:       map[string]bool{},
+		PlayerInfo: PlayerData{Cash: 6000},
+		RivalsInfo: []PlayerData{{Cash: 6000}, {Cash: 6000}},
+		Acquirer:   -1,
+	}
+	return st
+}
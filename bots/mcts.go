@@ -0,0 +1,446 @@
+package bots
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/player"
+)
+
+// Default values of MCTSParams fields
+const (
+	DefaultMCTSIterations   = 100
+	DefaultMCTSExploration  = 0.3
+	DefaultMCTSPlayoutDepth = 40
+)
+
+// MCTSParams holds the settings of the Monte Carlo tree search bot.
+// Search stops when any of Iterations or Duration is exhausted; if both
+// are zero, DefaultMCTSIterations is used.
+type MCTSParams struct {
+	// Iterations is the maximum number of playouts per decision
+	Iterations int
+	// Duration is the maximum time spent per decision
+	Duration time.Duration
+	// Exploration is the UCB1 exploration constant. If zero, DefaultMCTSExploration is used.
+	Exploration float64
+	// PlayoutDepth is the maximum number of actions simulated per playout, after which the
+	// simulated game is evaluated by players' net worth. If zero, DefaultMCTSPlayoutDepth is used.
+	PlayoutDepth int
+	// Rand is the generator used to sample hidden information. If nil, a generator
+	// initialised with the personality seed is used.
+	Rand *rand.Rand
+	// Personality tunes the decisions which are not searched, taken as the greedy bot would
+	Personality Config
+}
+
+// MCTS is a struct which implements an AI based on information set Monte Carlo tree search.
+// Every search iteration samples the hidden information (rival hands and tiles order) and
+// walks down a tree of moves of all players shared by all samples, choosing at every node
+// the move of the player in turn with UCB1, until it reaches a move not explored yet. That
+// move is added to the tree and the rest of the game is simulated with greedy bots, the
+// outcome being credited to every move in the path. The bot plays the move explored the most.
+// Tree nodes cover every decision of the game, but the search only starts from tile plays and
+// stock purchases: the bot status does not tell the tiles of a corporation being founded nor
+// the pending order of a merge, so these decisions, as well as claiming the end of the game,
+// are taken as the greedy bot would.
+type MCTS struct {
+	*base
+	params MCTSParams
+	greedy *Greedy
+	// simGreedy suggests stock purchases for the players of simulated games
+	simGreedy *Greedy
+}
+
+// A node of the search tree, reached by playing its move
+type node struct {
+	move Message
+	// key identifies the move across simulated games
+	key string
+	// mover is the number of the player who plays the move in simulated games
+	mover    int
+	parent   *node
+	children []*node
+	visits   int
+	// avails is the number of times the move was available when its parent was visited
+	avails int
+	// rewards holds the sum of the outcomes of the playouts through the node, for every player
+	rewards []float64
+}
+
+// NewMCTS returns a new instance of the Monte Carlo tree search AI bot
+func NewMCTS(params MCTSParams) *MCTS {
+	if params.Iterations == 0 && params.Duration == 0 {
+		params.Iterations = DefaultMCTSIterations
+	}
+	if params.Exploration == 0 {
+		params.Exploration = DefaultMCTSExploration
+	}
+	if params.PlayoutDepth == 0 {
+		params.PlayoutDepth = DefaultMCTSPlayoutDepth
+	}
+	if params.Rand == nil {
+		params.Rand = params.Personality.rand()
+	}
+	return &MCTS{
+		&base{},
+		params,
+		NewGreedy(params.Personality),
+		NewGreedy(Config{Seed: 1}),
+	}
+}
+
+// Play analyses the current game status and returns a message with the
+// next play movement by the bot AI
+func (m *MCTS) Play() interface{} {
+	m.greedy.Update(m.status)
+	if m.status.State != interfaces.PlayTileStateName && m.status.State != interfaces.BuyStockStateName {
+		return m.greedy.Play()
+	}
+	if !m.status.IsLastRound && m.greedy.claimEndGame() {
+		return m.greedy.Play()
+	}
+	candidates := candidateMoves(m.status, m.greedy)
+	if len(candidates) < 2 {
+		return m.greedy.Play()
+	}
+	return m.search(candidates)
+}
+
+// Grows the search tree from the passed candidate moves and returns the most visited one
+func (m *MCTS) search(candidates []Message) Message {
+	root := m.grow(candidates)
+	best := root.children[0]
+	for _, child := range root.children {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	return best.move
+}
+
+// Runs the search iterations, returning the root of the resulting tree
+func (m *MCTS) grow(candidates []Message) *node {
+	root := &node{mover: -1}
+	var deadline time.Time
+	if m.params.Duration > 0 {
+		deadline = time.Now().Add(m.params.Duration)
+	}
+	for i := 0; m.params.Iterations == 0 || i < m.params.Iterations; i++ {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		game, err := m.determinize()
+		if err != nil {
+			break
+		}
+		current := root
+		for game.GameStateName() != interfaces.EndGameStateName {
+			number := game.CurrentPlayerNumber()
+			moves := candidates
+			if current != root {
+				moves = m.movesFor(game, number)
+			}
+			child, isNew := current.selectChild(moves, number, m.params.Exploration, m.params.Rand)
+			if child == nil || Apply(game, number, child.move) != nil {
+				break
+			}
+			if isNew {
+				current.children = append(current.children, child)
+			}
+			current = child
+			if isNew {
+				break
+			}
+		}
+		rewards := m.playout(game)
+		for n := current; n != nil; n = n.parent {
+			n.visits++
+			if n.rewards == nil {
+				n.rewards = make([]float64, len(rewards))
+			}
+			for player, reward := range rewards {
+				n.rewards[player] += reward
+			}
+		}
+	}
+	if len(root.children) == 0 {
+		root.children = append(root.children, &node{move: candidates[0]})
+	}
+	return root
+}
+
+// Returns the moves the passed player of a simulated game can choose from
+func (m *MCTS) movesFor(game *acquire.Game, number int) []Message {
+	st, err := StatusFor(game, number)
+	if err != nil {
+		return nil
+	}
+	return candidateMoves(st, m.simGreedy)
+}
+
+// Returns the child to explore among the passed available moves, to be played by the passed
+// player. Moves not explored yet go first, in random order, and are returned as a new child
+// not added to the tree yet. Otherwise, the child is chosen following UCB1 from the point of
+// view of the player.
+func (n *node) selectChild(moves []Message, mover int, exploration float64, rn *rand.Rand) (*node, bool) {
+	untried := []Message{}
+	available := []*node{}
+	for _, move := range moves {
+		key := moveKey(move)
+		var found *node
+		for _, child := range n.children {
+			if child.key == key {
+				found = child
+				break
+			}
+		}
+		if found == nil {
+			untried = append(untried, move)
+			continue
+		}
+		found.avails++
+		available = append(available, found)
+	}
+	if len(untried) > 0 {
+		move := untried[rn.Intn(len(untried))]
+		return &node{move: move, key: moveKey(move), mover: mover, parent: n, avails: 1}, true
+	}
+	var best *node
+	bestScore := math.Inf(-1)
+	for _, child := range available {
+		if child.visits == 0 {
+			return child, false
+		}
+		score := child.rewards[mover]/float64(child.visits) + exploration*math.Sqrt(math.Log(float64(child.avails))/float64(child.visits))
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best, false
+}
+
+// Returns a string identifying the passed move, equal for equal moves
+func moveKey(move Message) string {
+	data, _ := json.Marshal(move)
+	return string(data)
+}
+
+// Plays the simulated game with greedy bots until it ends or the playout depth is reached,
+// returning the net worth of every player relative to the richest one
+func (m *MCTS) playout(game *acquire.Game) []float64 {
+	bot := NewGreedy(Config{Seed: 1})
+	for i := 0; i < m.params.PlayoutDepth && game.GameStateName() != interfaces.EndGameStateName; i++ {
+		number := game.CurrentPlayerNumber()
+		st, err := StatusFor(game, number)
+		if err != nil {
+			break
+		}
+		bot.Update(st)
+		if err = Apply(game, number, bot.Play().(Message)); err != nil {
+			break
+		}
+	}
+	rewards := make([]float64, game.NumberPlayers())
+	var richest int
+	for _, valuation := range game.Valuations() {
+		if valuation.NetWorth > richest {
+			richest = valuation.NetWorth
+		}
+	}
+	if richest == 0 {
+		return rewards
+	}
+	for _, valuation := range game.Valuations() {
+		rewards[valuation.Player] = float64(valuation.NetWorth) / float64(richest)
+	}
+	return rewards
+}
+
+// Builds a game from the bot status, in which the bot is player 0 and rivals follow
+// in seating order. Rival hands and tileset order are sampled from the tiles
+// neither on board, in the bot's hand nor discarded.
+func (m *MCTS) determinize() (*acquire.Game, error) {
+	discarded := map[string]bool{}
+	for _, coords := range m.status.DiscardedTiles {
+		discarded[coords] = true
+	}
+	unseen := []string{}
+	for _, coords := range boardCoords {
+		if _, inHand := m.status.Hand[coords]; !inHand && !discarded[coords] && (m.status.Board[coords] == interfaces.EmptyOwner || m.status.Board[coords] == "") {
+			unseen = append(unseen, coords)
+		}
+	}
+	m.params.Rand.Shuffle(len(unseen), func(i, j int) { unseen[i], unseen[j] = unseen[j], unseen[i] })
+
+	snapshot := acquire.Snapshot{
+		Version:        acquire.SnapshotVersion,
+		Seed:           m.params.Rand.Int63() + 1,
+		State:          m.status.State,
+		Board:          map[string]int{},
+		Round:          1,
+		IsLastRound:    m.status.IsLastRound,
+		DiscardedTiles: m.status.DiscardedTiles,
+	}
+	for coords, owner := range m.status.Board {
+		if owner == interfaces.UnincorporatedOwner {
+			snapshot.Board[coords] = -1
+		} else if index, err := strconv.Atoi(owner); err == nil {
+			snapshot.Board[coords] = index
+		}
+	}
+	for i, corp := range m.status.Corps {
+		snapshot.Corporations[i] = acquire.CorporationSnapshot{Size: corp.Size, Stock: corp.RemainingShares}
+	}
+	hand := []string{}
+	for coords := range m.status.Hand {
+		hand = append(hand, coords)
+	}
+	sort.Strings(hand)
+	snapshot.Players = append(snapshot.Players, playerSnapshot(m.status.PlayerInfo, hand))
+	for _, rival := range rivalsInfo(m.status) {
+		n := 6
+		if n > len(unseen) {
+			n = len(unseen)
+		}
+		snapshot.Players = append(snapshot.Players, playerSnapshot(rival, unseen[:n]))
+		unseen = unseen[n:]
+	}
+	snapshot.Tileset = unseen
+
+	players := make([]interfaces.Player, len(snapshot.Players))
+	for i := range players {
+		players[i] = player.New()
+	}
+	return acquire.Restore(snapshot, players, acquire.Optional{})
+}
+
+func playerSnapshot(data PlayerData, tiles []string) acquire.PlayerSnapshot {
+	return acquire.PlayerSnapshot{
+		Cash:   data.Cash,
+		Shares: data.OwnedShares,
+		Tiles:  append([]string{}, tiles...),
+		Active: true,
+	}
+}
+
+// Returns the moves considered for the player whose status is passed. Stock purchases
+// are suggested by the passed greedy bot, which gets its status updated.
+func candidateMoves(st Status, greedy *Greedy) []Message {
+	switch st.State {
+	case interfaces.PlayTileStateName:
+		return tileCandidates(st)
+	case interfaces.FoundCorpStateName:
+		return foundCandidates(st)
+	case interfaces.BuyStockStateName:
+		greedy.Update(st)
+		return buyCandidates(st, greedy.buyStock())
+	case interfaces.SellTradeStateName:
+		return sellTradeCandidates(st)
+	case interfaces.UntieMergeStateName:
+		return untieCandidates(st)
+	}
+	return nil
+}
+
+// Returns a play tile message for every playable tile in hand
+func tileCandidates(st Status) []Message {
+	coords := []string{}
+	for c, playable := range st.Hand {
+		if playable {
+			coords = append(coords, c)
+		}
+	}
+	sort.Strings(coords)
+	candidates := []Message{}
+	for _, c := range coords {
+		candidates = append(candidates, Message{Type: PlayTileResponseType, Params: PlayTileResponseParams{Tile: c}})
+	}
+	return candidates
+}
+
+// Returns a message founding every corporation not on board
+func foundCandidates(st Status) []Message {
+	candidates := []Message{}
+	for index, corp := range st.Corps {
+		if corp.Size == 0 {
+			candidates = append(candidates, Message{Type: NewCorpResponseType, Params: NewCorpResponseParams{CorporationIndex: index}})
+		}
+	}
+	return candidates
+}
+
+// Returns buy messages for the passed greedy choice, buying nothing and buying
+// from 1 to 3 shares of a single corporation. Mixed buys other than the greedy one are
+// left out, as there would be too many candidates to explore them properly.
+func buyCandidates(st Status, greedy BuyResponseParams) []Message {
+	candidates := []Message{
+		{Type: BuyResponseType, Params: greedy},
+	}
+	if len(greedy.CorporationsIndexes) > 0 {
+		candidates = append(candidates, Message{Type: BuyResponseType, Params: BuyResponseParams{CorporationsIndexes: map[string]int{}}})
+	}
+	for index, corp := range st.Corps {
+		key := strconv.Itoa(index)
+		for amount := 1; amount <= maxBuyableShares; amount++ {
+			if corp.Size == 0 || corp.RemainingShares < amount || corp.Price*amount > st.PlayerInfo.Cash {
+				break
+			}
+			if len(greedy.CorporationsIndexes) == 1 && greedy.CorporationsIndexes[key] == amount {
+				continue
+			}
+			candidates = append(candidates, Message{Type: BuyResponseType, Params: BuyResponseParams{CorporationsIndexes: map[string]int{key: amount}}})
+		}
+	}
+	return candidates
+}
+
+// Returns messages selling all shares of the defunct corporation, holding all of them,
+// and trading as many as possible while either selling or holding the rest
+func sellTradeCandidates(st Status) []Message {
+	options := []map[string]SellTrade{}
+	for index, corp := range st.Corps {
+		owned := st.PlayerInfo.OwnedShares[index]
+		if !corp.Defunct || owned == 0 {
+			continue
+		}
+		key := strconv.Itoa(index)
+		options = append(options, map[string]SellTrade{key: {Sell: owned}}, map[string]SellTrade{})
+		if st.Acquirer == -1 {
+			continue
+		}
+		traded := owned / 2 * 2
+		if traded > st.Corps[st.Acquirer].RemainingShares*2 {
+			traded = st.Corps[st.Acquirer].RemainingShares * 2
+		}
+		if traded > 0 {
+			options = append(options, map[string]SellTrade{key: {Trade: traded, Sell: owned - traded}})
+			if traded < owned {
+				options = append(options, map[string]SellTrade{key: {Trade: traded}})
+			}
+		}
+	}
+	if len(options) == 0 {
+		options = append(options, map[string]SellTrade{})
+	}
+	candidates := []Message{}
+	for _, option := range options {
+		candidates = append(candidates, Message{Type: SellTradeResponseType, Params: SellTradeResponseParams{CorporationsIndexes: option}})
+	}
+	return candidates
+}
+
+// Returns a message choosing every tied corporation
+func untieCandidates(st Status) []Message {
+	candidates := []Message{}
+	for _, index := range st.TiedCorps {
+		candidates = append(candidates, Message{Type: UntieMergeResponseType, Params: UntieMergeResponseParams{CorporationIndex: index}})
+	}
+	return candidates
+}
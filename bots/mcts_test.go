@@ -0,0 +1,166 @@
+package bots
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/player"
+)
+
+func TestMCTSPlaysFullGame(t *testing.T) {
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 4})
+	seats := []interfaces.Bot{
+		NewMCTS(MCTSParams{Iterations: 20, PlayoutDepth: 20, Rand: rand.New(rand.NewSource(1))}),
+		NewGreedy(Config{}),
+		NewGreedy(Config{}),
+	}
+
+	for i := 0; i < 500 && game.GameStateName() != interfaces.EndGameStateName; i++ {
+		number := game.CurrentPlayerNumber()
+		st, _ := StatusFor(game, number)
+		seats[number].Update(st)
+		msg := seats[number].Play().(Message)
+		if err := Apply(game, number, msg); err != nil {
+			t.Fatalf("Message %v of player %d must be applied, got error %s", msg, number, err)
+		}
+	}
+	if game.GameStateName() != interfaces.EndGameStateName {
+		t.Errorf("Game must end, got state %s", game.GameStateName())
+	}
+}
+
+func TestMCTSDeterminizeKeepsKnownInformation(t *testing.T) {
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 4})
+	bot := NewMCTS(MCTSParams{Rand: rand.New(rand.NewSource(1))})
+	st, _ := StatusFor(game, game.CurrentPlayerNumber())
+	bot.Update(st)
+
+	sim, err := bot.determinize()
+	if err != nil {
+		t.Fatalf("Status must be determinized, got error %s", err)
+	}
+	simStatus, _ := StatusFor(sim, 0)
+	for coords := range st.Hand {
+		if _, ok := simStatus.Hand[coords]; !ok {
+			t.Errorf("Tile %s in hand must be kept in the simulated game", coords)
+		}
+	}
+	for coords, owner := range st.Board {
+		if simStatus.Board[coords] != owner {
+			t.Errorf("Cell %s must be %s in the simulated game, got %s", coords, owner, simStatus.Board[coords])
+		}
+	}
+	if len(sim.Player(1).Tiles()) != 6 || len(sim.Player(2).Tiles()) != 6 {
+		t.Errorf("Rivals must be dealt a sampled hand")
+	}
+}
+
+func TestMCTSDeterminizeLeavesOutDiscardedTiles(t *testing.T) {
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 4})
+	bot := NewMCTS(MCTSParams{Rand: rand.New(rand.NewSource(1))})
+	st, _ := StatusFor(game, game.CurrentPlayerNumber())
+	st.DiscardedTiles = []string{}
+	for _, coords := range boardCoords {
+		if _, inHand := st.Hand[coords]; !inHand && st.Board[coords] == interfaces.EmptyOwner && len(st.DiscardedTiles) < 10 {
+			st.DiscardedTiles = append(st.DiscardedTiles, coords)
+		}
+	}
+	bot.Update(st)
+
+	for i := 0; i < 10; i++ {
+		sim, err := bot.determinize()
+		if err != nil {
+			t.Fatalf("Status must be determinized, got error %s", err)
+		}
+		snapshot := sim.Snapshot()
+		dealt := append([]string{}, snapshot.Tileset...)
+		for _, pl := range snapshot.Players[1:] {
+			dealt = append(dealt, pl.Tiles...)
+		}
+		for _, coords := range dealt {
+			for _, discarded := range st.DiscardedTiles {
+				if coords == discarded {
+					t.Errorf("Discarded tile %s must not be dealt in the simulated game", coords)
+				}
+			}
+		}
+		if !reflect.DeepEqual(snapshot.DiscardedTiles, st.DiscardedTiles) {
+			t.Errorf("Simulated game must keep discarded tiles %v, got %v", st.DiscardedTiles, snapshot.DiscardedTiles)
+		}
+	}
+}
+
+func TestMCTSBuyCandidates(t *testing.T) {
+	st := emptyStatus(interfaces.BuyStockStateName)
+	st.Corps[0] = CorpData{Size: 2, Price: 200, RemainingShares: 25}
+	st.Corps[1] = CorpData{Size: 2, Price: 200, RemainingShares: 1}
+
+	// Greedy choice (3 shares of corporation 0), nothing, 1 and 2 shares of corporation 0 and 1 of corporation 1
+	candidates := candidateMoves(st, NewGreedy(Config{}))
+	if len(candidates) != 5 {
+		t.Errorf("Expected %d buy candidates, got %d", 5, len(candidates))
+	}
+}
+
+func TestMCTSSellTradeCandidates(t *testing.T) {
+	st := emptyStatus(interfaces.SellTradeStateName)
+	st.Corps[0] = CorpData{Size: 5, Price: 300, RemainingShares: 20, Defunct: true}
+	st.Corps[1] = CorpData{Size: 8, Price: 500, RemainingShares: 1}
+	st.Acquirer = 1
+	st.PlayerInfo.OwnedShares[0] = 5
+
+	// Selling all, holding all, trading 2 and selling or holding the other 3
+	expected := []SellTradeResponseParams{
+		{CorporationsIndexes: map[string]SellTrade{"0": {Sell: 5}}},
+		{CorporationsIndexes: map[string]SellTrade{}},
+		{CorporationsIndexes: map[string]SellTrade{"0": {Sell: 3, Trade: 2}}},
+		{CorporationsIndexes: map[string]SellTrade{"0": {Trade: 2}}},
+	}
+	candidates := candidateMoves(st, NewGreedy(Config{}))
+	if len(candidates) != len(expected) {
+		t.Fatalf("Expected %d sell trade candidates, got %d", len(expected), len(candidates))
+	}
+	for i, params := range expected {
+		if !reflect.DeepEqual(candidates[i].Params, params) {
+			t.Errorf("Expected candidate %v, got %v", params, candidates[i].Params)
+		}
+	}
+}
+
+func TestMCTSGrowsTree(t *testing.T) {
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 4})
+	bot := NewMCTS(MCTSParams{Iterations: 60, PlayoutDepth: 10, Rand: rand.New(rand.NewSource(1))})
+	st, _ := StatusFor(game, game.CurrentPlayerNumber())
+	bot.Update(st)
+
+	candidates := candidateMoves(st, bot.greedy)
+	root := bot.grow(candidates)
+	if len(root.children) != len(candidates) {
+		t.Errorf("Every candidate must be explored, expected %d children, got %d", len(candidates), len(root.children))
+	}
+	if root.visits != 60 {
+		t.Errorf("Root must be visited in every iteration, got %d visits", root.visits)
+	}
+	rivalMoves := 0
+	for _, child := range root.children {
+		if child.mover != 0 {
+			t.Errorf("Root moves must be played by the bot, got player %d", child.mover)
+		}
+		for _, grandchild := range child.children {
+			if grandchild.parent != child || grandchild.mover == 0 {
+				t.Errorf("Move %s must be played by a rival after move %s", grandchild.key, child.key)
+			}
+			rivalMoves++
+		}
+	}
+	if rivalMoves == 0 {
+		t.Errorf("Tree must grow beyond the root moves")
+	}
+}
@@ -1,5 +1,10 @@
 package bots
 
+import (
+	"encoding/json"
+	"errors"
+)
+
 // Message types returned by bots
 const (
 	PlayTileResponseType   = "playTile"
@@ -48,3 +53,62 @@ type SellTrade struct {
 type UntieMergeResponseParams struct {
 	CorporationIndex int
 }
+
+// ErrorResponseType is the type of the messages sent by out-of-process bots to report
+// they could not decide a move. Its params hold the error description.
+const ErrorResponseType = "error"
+
+// UnmarshalJSON decodes a message, storing in Params the struct corresponding to its type
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type   string
+		Params json.RawMessage
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	m.Type = raw.Type
+	var err error
+	switch raw.Type {
+	case PlayTileResponseType:
+		var params PlayTileResponseParams
+		err = unmarshalParams(raw.Params, &params)
+		m.Params = params
+	case NewCorpResponseType:
+		var params NewCorpResponseParams
+		err = unmarshalParams(raw.Params, &params)
+		m.Params = params
+	case BuyResponseType:
+		var params BuyResponseParams
+		err = unmarshalParams(raw.Params, &params)
+		m.Params = params
+	case SellTradeResponseType:
+		var params SellTradeResponseParams
+		err = unmarshalParams(raw.Params, &params)
+		m.Params = params
+	case UntieMergeResponseType:
+		var params UntieMergeResponseParams
+		err = unmarshalParams(raw.Params, &params)
+		m.Params = params
+	case ErrorResponseType:
+		var params string
+		err = unmarshalParams(raw.Params, &params)
+		m.Params = params
+	case EndGameResponseType:
+		m.Params = nil
+	default:
+		return errors.New(UnknownMessageType)
+	}
+	if err != nil {
+		return errors.New(InvalidMessageParams)
+	}
+	return nil
+}
+
+// Decodes message params, which are optional
+func unmarshalParams(data json.RawMessage, params interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(data, params)
+}
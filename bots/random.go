@@ -3,6 +3,7 @@ package bots
 
 import (
 	"math/rand"
+	"sort"
 	"strconv"
 	"time"
 
@@ -12,18 +13,27 @@ import (
 const (
 	endGameCorporationSize = 41
 	safeCorporationSize    = 11
+	// Stock shares each corporation has at the beginning of the game
+	totalShares = 25
 )
 
 // Random is a struct which implements a very stupid AI, which basically
 // chooses all its decisions randomly (So not that much an AI but an AS)
 type Random struct {
 	*base
+	rn *rand.Rand
 }
 
-// NewRandom returns a new instance of the random AI bot
-func NewRandom() *Random {
+// NewRandom returns a new instance of the random AI bot, which takes its
+// decisions using the passed random generator. If rn is nil a generator
+// seeded with the current time is used.
+func NewRandom(rn *rand.Rand) *Random {
+	if rn == nil {
+		rn = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
 	return &Random{
 		&base{},
+		rn,
 	}
 }
 
@@ -70,10 +80,8 @@ func (r *Random) Play() interface{} {
 }
 
 func (r *Random) playTile() PlayTileResponseParams {
-	source := rand.NewSource(time.Now().UnixNano())
-	rn := rand.New(source)
 	tileCoords := r.tileCoords()
-	tileNumber := rn.Intn(len(tileCoords))
+	tileNumber := r.rn.Intn(len(tileCoords))
 
 	return PlayTileResponseParams{
 		Tile: tileCoords[tileNumber],
@@ -81,7 +89,8 @@ func (r *Random) playTile() PlayTileResponseParams {
 }
 
 // As the tiles in hand come as a map, we need to store its coordinates in an array
-// before selecting a random one (only the playable ones)
+// before selecting a random one (only the playable ones). Coordinates are sorted
+// so the selection does not depend on map iteration order.
 func (r *Random) tileCoords() []string {
 	coords := make([]string, 0, len(r.status.Hand))
 	for k, playable := range r.status.Hand {
@@ -89,16 +98,15 @@ func (r *Random) tileCoords() []string {
 			coords = append(coords, k)
 		}
 	}
+	sort.Strings(coords)
 	return coords
 }
 
 func (r *Random) foundCorporation() NewCorpResponseParams {
-	source := rand.NewSource(time.Now().UnixNano())
-	rn := rand.New(source)
 	var corpNumber int
 	response := NewCorpResponseParams{}
 	for {
-		corpNumber = rn.Intn(len(r.status.Corps))
+		corpNumber = r.rn.Intn(len(r.status.Corps))
 		if r.status.Corps[corpNumber].Size == 0 {
 			response.CorporationIndex = corpNumber
 			break
@@ -109,14 +117,12 @@ func (r *Random) foundCorporation() NewCorpResponseParams {
 
 // buyStock buys stock from a random active corporation
 func (r *Random) buyStock() BuyResponseParams {
-	source := rand.NewSource(time.Now().UnixNano())
-	rn := rand.New(source)
 	buy := 0
 	var corpIndex int
 	var corp CorpData
 
 	for {
-		corpIndex = rn.Intn(len(r.status.Corps))
+		corpIndex = r.rn.Intn(len(r.status.Corps))
 		corp = r.status.Corps[corpIndex]
 		if corp.Size > 0 {
 			break
@@ -162,20 +168,5 @@ func (r *Random) untieMerge() UntieMergeResponseParams {
 }
 
 func (r *Random) claimEndGame() bool {
-	var active, safe int
-	for _, corp := range r.status.Corps {
-		if corp.Size >= endGameCorporationSize {
-			return true
-		}
-		if corp.Size > 0 {
-			active++
-		}
-		if corp.Size >= safeCorporationSize {
-			safe++
-		}
-	}
-	if active > 0 && active == safe {
-		return true
-	}
-	return false
+	return endConditionsReached(r.status)
 }
@@ -1,5 +1,14 @@
 package bots
 
+import (
+	"errors"
+	"strconv"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/tile"
+)
+
 // Status is a struct used by bot implementations to know about the current
 // status of a game.
 type Status struct {
@@ -12,6 +21,13 @@ type Status struct {
 	PlayerInfo  PlayerData
 	RivalsInfo  []PlayerData
 	IsLastRound bool
+	// Acquirer is the index of the corporation surviving the merge in progress, or -1 if there is none
+	Acquirer int
+	// DiscardedTiles holds the coordinates of the permanently unplayable tiles set aside by players
+	DiscardedTiles []string
+	// HiddenAssets is true if players' cash and stock shares are kept secret in the game,
+	// in which case rivals' cash and owned shares are always zero in RivalsInfo
+	HiddenAssets bool
 }
 
 // CorpData is a struct which holds data about a corporation in a game.
@@ -31,3 +47,111 @@ type PlayerData struct {
 	Cash        int
 	OwnedShares [7]int
 }
+
+// StatusFor returns the status of the passed game as seen by the player with the passed
+// number, which is all a bot playing on behalf of that player is allowed to know.
+// Only the player's own hand is included; rivals are the rest of active players, in
+// seating order starting with the one after the player.
+// Board maps the coordinates of every cell to "empty", "unincorporated" or the index
+// of the corporation which owns it. The only corporation marked as defunct is the one
+// whose shares are being sold or traded, if any.
+// In hidden assets games, rivals' cash and stock shares are not included.
+func StatusFor(game *acquire.Game, playerNumber int) (Status, error) {
+	if playerNumber < 0 || playerNumber >= game.NumberPlayers() {
+		return Status{}, errors.New(PlayerNotFound)
+	}
+	corps := game.Corporations()
+	st := Status{
+		Board:        boardStatus(game.Board(), corps),
+		State:        game.GameStateName(),
+		Hand:         map[string]bool{},
+		TiedCorps:    []int{},
+		Acquirer:     corporationIndex(game.Acquirer(), corps),
+		PlayerInfo:   playerData(game.Player(playerNumber), corps),
+		RivalsInfo:   []PlayerData{},
+		IsLastRound:  game.IsLastRound(),
+		HiddenAssets: game.HiddenAssets(),
+	}
+	for _, tl := range game.Player(playerNumber).Tiles() {
+		st.Hand[tile.Coords(tl)] = game.IsTilePlayable(tl)
+	}
+	for _, tl := range game.DiscardedTiles() {
+		st.DiscardedTiles = append(st.DiscardedTiles, tile.Coords(tl))
+	}
+	for i, corp := range corps {
+		st.Corps[i] = CorpData{
+			Price:           corp.StockPrice(),
+			MajorityBonus:   corp.MajorityBonus(),
+			MinorityBonus:   corp.MinorityBonus(),
+			RemainingShares: corp.Stock(),
+			Size:            corp.Size(),
+			Defunct:         corp == game.CurrentDefunct(),
+		}
+	}
+	for _, tied := range game.TiedCorps() {
+		st.TiedCorps = append(st.TiedCorps, corporationIndex(tied, corps))
+	}
+	for i := 1; i < game.NumberPlayers(); i++ {
+		rival := game.Player((playerNumber + i) % game.NumberPlayers())
+		if !rival.Active() {
+			continue
+		}
+		if st.HiddenAssets {
+			st.RivalsInfo = append(st.RivalsInfo, PlayerData{})
+		} else {
+			st.RivalsInfo = append(st.RivalsInfo, playerData(rival, corps))
+		}
+	}
+	return st, nil
+}
+
+// All board cells, with their coordinates precalculated as status is built very often
+// when simulating games
+var boardCells = func() []*tile.Tile {
+	cells := []*tile.Tile{}
+	for number := 1; number < 13; number++ {
+		for _, letter := range tile.Letters {
+			cells = append(cells, tile.New(number, letter))
+		}
+	}
+	return cells
+}()
+
+var boardCoords = func() []string {
+	coords := make([]string, len(boardCells))
+	for i, cell := range boardCells {
+		coords[i] = tile.Coords(cell)
+	}
+	return coords
+}()
+
+func boardStatus(bd interfaces.Board, corps [7]interfaces.Corporation) map[string]string {
+	cells := make(map[string]string, len(boardCells))
+	for i, tl := range boardCells {
+		cell := bd.Cell(tl.Number(), tl.Letter())
+		if cell.Type() == interfaces.CorporationOwner {
+			cells[boardCoords[i]] = strconv.Itoa(corporationIndex(cell.(interfaces.Corporation), corps))
+		} else {
+			cells[boardCoords[i]] = cell.Type()
+		}
+	}
+	return cells
+}
+
+func playerData(pl interfaces.Player, corps [7]interfaces.Corporation) PlayerData {
+	data := PlayerData{Cash: pl.Cash()}
+	for i, corp := range corps {
+		data.OwnedShares[i] = pl.Shares(corp)
+	}
+	return data
+}
+
+// Returns the position of the passed corporation in the corporations array, or -1 if not found
+func corporationIndex(corp interfaces.Corporation, corps [7]interfaces.Corporation) int {
+	for i := range corps {
+		if corps[i] == corp {
+			return i
+		}
+	}
+	return -1
+}
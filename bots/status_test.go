@@ -0,0 +1,80 @@
+package bots
+
+import (
+	"testing"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/player"
+	"github.com/svera/acquire/tile"
+)
+
+func TestStatusFor(t *testing.T) {
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 5})
+	players[2].AddCash(100)
+	players[0].AddCash(200)
+
+	st, err := StatusFor(game, 1)
+	if err != nil {
+		t.Fatalf("Status must be built for an existing player, got error %s", err)
+	}
+	if len(st.Board) != 108 {
+		t.Errorf("Board must have all %d cells, got %d", 108, len(st.Board))
+	}
+	for _, tl := range game.OpeningTiles() {
+		if st.Board[tile.Coords(tl)] != interfaces.UnincorporatedOwner {
+			t.Errorf("Opening tile %s must be unincorporated, got %s", tile.Coords(tl), st.Board[tile.Coords(tl)])
+		}
+	}
+	if len(st.Hand) != 6 {
+		t.Errorf("Hand must have %d tiles, got %d", 6, len(st.Hand))
+	}
+	for _, tl := range players[1].Tiles() {
+		if _, ok := st.Hand[tile.Coords(tl)]; !ok {
+			t.Errorf("Tile %s of the player must be in hand", tile.Coords(tl))
+		}
+	}
+	if len(st.RivalsInfo) != 2 || st.RivalsInfo[0].Cash != 6100 || st.RivalsInfo[1].Cash != 6200 {
+		t.Errorf("Rivals must be in seating order starting after the player, got %v", st.RivalsInfo)
+	}
+	if st.State != interfaces.PlayTileStateName {
+		t.Errorf("Status must be %s, got %s", interfaces.PlayTileStateName, st.State)
+	}
+}
+
+func TestStatusForInexistentPlayer(t *testing.T) {
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 5})
+
+	if _, err := StatusFor(game, 3); err == nil || err.Error() != PlayerNotFound {
+		t.Errorf("Status for an inexistent player must return error %s", PlayerNotFound)
+	}
+}
+
+func TestStatusForHiddenAssets(t *testing.T) {
+	players := []interfaces.Player{player.New(), player.New(), player.New()}
+	game, _ := acquire.New(players, acquire.Optional{Seed: 5, HiddenAssets: true})
+	corp := game.Corporations()[1]
+	players[0].AddShares(corp, 3)
+	players[2].AddShares(corp, 2)
+	corp.RemoveStock(5)
+
+	st, _ := StatusFor(game, 1)
+	if !st.HiddenAssets {
+		t.Errorf("Status must tell assets are hidden")
+	}
+	if st.PlayerInfo.Cash != 6000 {
+		t.Errorf("Status must include the player's own cash, got %d", st.PlayerInfo.Cash)
+	}
+	for _, rival := range st.RivalsInfo {
+		if rival.Cash != 0 || rival.OwnedShares != [7]int{} {
+			t.Errorf("Status must not include rivals' cash nor shares in hidden assets games, got %v", rival)
+		}
+	}
+	for _, rival := range rivalsInfo(st) {
+		if rival.Cash != 6000 || rival.OwnedShares[1] < 2 || rival.OwnedShares[1] > 3 {
+			t.Errorf("Rivals' assets must be estimated from the bank and the player's own ones, got %v", rival)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Command acquire-random-bot is a reference out-of-process bot which plays random moves,
+// talking the line-delimited JSON protocol over its standard input and output.
+// It can be used to test out-of-process bot support, e.g. with
+//
+//	acquire-runner -bots external,random,random -external "acquire-random-bot -seed 1"
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/svera/acquire/bots"
+)
+
+func main() {
+	seed := flag.Int64("seed", 0, "random generator seed, 0 for a time based one")
+	flag.Parse()
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	if err := bots.Serve(os.Stdin, os.Stdout, bots.NewRandom(rand.New(rand.NewSource(*seed)))); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,70 @@
+// Command acquire-runner plays a full Acquire game between bots and prints its result
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/svera/acquire/bots"
+	"github.com/svera/acquire/runner"
+)
+
+func main() {
+	botNames := flag.String("bots", "random,random,random", "comma separated list of bots, one per seat")
+	difficulty := flag.String("difficulty", "medium", "difficulty level of all bots: easy, medium or hard")
+	external := flag.String("external", "", "command line of the bots named external, run as subprocesses")
+	externalTimeout := flag.Duration("external-timeout", bots.DefaultExternalTimeout, "time external bots have to answer every request")
+	seed := flag.Int64("seed", 0, "game seed, 0 for a time based one")
+	maxActions := flag.Int("max-actions", runner.DefaultMaxActions, "maximum number of bot actions before giving up")
+	hiddenAssets := flag.Bool("hidden-assets", false, "keep players' cash and stock shares secret")
+	asJSON := flag.Bool("json", false, "print the result as JSON, including the game log")
+	list := flag.Bool("list", false, "list the available bots and exit")
+	flag.Parse()
+
+	if *list {
+		for _, info := range bots.Available() {
+			fmt.Printf("%s\t%s\n", info.Name, info.Description)
+		}
+		return
+	}
+
+	names := strings.Split(*botNames, ",")
+	configs := make([]bots.Config, len(names))
+	for i := range configs {
+		configs[i] = bots.Config{Difficulty: *difficulty}
+		if names[i] == "external" {
+			configs[i].Options = map[string]string{"command": *external, "timeout": externalTimeout.String()}
+		}
+	}
+	res, err := runner.Run(runner.Config{
+		Bots:         names,
+		BotConfigs:   configs,
+		Seed:         *seed,
+		MaxActions:   *maxActions,
+		HiddenAssets: *hiddenAssets,
+	})
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(res)
+	} else {
+		printResult(res)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func printResult(res runner.Result) {
+	fmt.Printf("seed %d, %d actions\n", res.Seed, res.Actions)
+	for _, fault := range res.Faults {
+		fmt.Printf("player %d (%s) deactivated: %s\n", fault.Player, res.Bots[fault.Player], fault.Err)
+	}
+	for _, standing := range res.Standings {
+		fmt.Printf("%d. player %d (%s): %d$\n", standing.Rank, standing.Player, res.Bots[standing.Player], standing.Cash)
+	}
+}
@@ -0,0 +1,25 @@
+// Command acquire-server hosts Acquire games over HTTP and WebSocket, see package server
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/server"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	perMove := flag.Duration("per-move", 0, "time players have for every move, 0 for no limit")
+	perGame := flag.Duration("per-game", 0, "time players have for the whole game, 0 for no limit")
+	fallback := flag.String("fallback", acquire.FallbackAutoPlay, "what to do when a player runs out of time: autoPlay or deactivate")
+	flag.Parse()
+
+	srv := server.New(server.Config{
+		TimeControl: acquire.TimeControl{PerMove: *perMove, PerGame: *perGame, Fallback: *fallback},
+	})
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, srv))
+}
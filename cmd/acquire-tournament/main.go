@@ -0,0 +1,50 @@
+// Command acquire-tournament plays a tournament between bots and prints their ratings
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/svera/acquire/runner"
+	"github.com/svera/acquire/tournament"
+)
+
+func main() {
+	entrants := flag.String("bots", "random,random,random,random", "comma separated list of entrants")
+	format := flag.String("format", tournament.RoundRobin, "tournament format: roundRobin or swiss")
+	seats := flag.Int("seats", 3, "players per game, from 3 to 6")
+	rounds := flag.Int("rounds", 1, "round robin repetitions or Swiss rounds")
+	seed := flag.Int64("seed", 1, "seed of the first game")
+	workers := flag.Int("workers", 0, "games played in parallel, 0 for one per CPU")
+	maxActions := flag.Int("max-actions", runner.DefaultMaxActions, "maximum number of bot actions per game")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	report, err := tournament.Run(tournament.Config{
+		Entrants:   strings.Split(*entrants, ","),
+		Format:     *format,
+		Seats:      *seats,
+		Rounds:     *rounds,
+		Seed:       *seed,
+		Workers:    *workers,
+		MaxActions: *maxActions,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return
+	}
+	fmt.Printf("%d games\n", report.Games)
+	fmt.Printf("%-4s %-12s %7s %7s %9s %11s %10s %6s\n", "#", "bot", "rating", "games", "win rate", "avg cash", "unfinished", "faults")
+	for i, st := range report.Standings {
+		fmt.Printf("%-4d %-12s %7.0f %7d %8.1f%% %11.0f %10d %6d\n", i+1, st.Bot, st.Rating, st.Games, st.WinRate*100, st.AverageCash, st.Unfinished, st.Faults)
+	}
+}
@@ -0,0 +1,62 @@
+package acquire
+
+// Event types emitted by a game
+const (
+	TilePlacedEvent           = "tilePlaced"
+	TileDiscardedEvent        = "tileDiscarded"
+	CorporationFoundedEvent   = "corporationFounded"
+	FounderShareNotGivenEvent = "founderShareNotGiven"
+	CorporationGrownEvent     = "corporationGrown"
+	CorporationDefunctEvent   = "corporationDefunct"
+	BonusPaidEvent            = "bonusPaid"
+	SharesBoughtEvent         = "sharesBought"
+	SharesSoldEvent           = "sharesSold"
+	SharesTradedEvent         = "sharesTraded"
+	PlayerDeactivatedEvent    = "playerDeactivated"
+	TurnPassedEvent           = "turnPassed"
+	GameEndedEvent            = "gameEnded"
+	PlayerTimedOutEvent       = "playerTimedOut"
+)
+
+// Event describes a change in the state of a game.
+// Player and Corporation are -1 when not relevant for the event type.
+// Depending on the event type, Amount holds:
+//   - BonusPaidEvent: cash received by the player
+//   - SharesBoughtEvent: cash paid by the player
+//   - SharesSoldEvent: cash received by the player
+//   - SharesTradedEvent: shares of the acquirer corporation received by the player
+//   - CorporationFoundedEvent, CorporationGrownEvent: corporation size
+type Event struct {
+	Type        string `json:"type"`
+	Player      int    `json:"player"`
+	Corporation int    `json:"corporation"`
+	Tile        string `json:"tile,omitempty"`
+	Shares      int    `json:"shares,omitempty"`
+	Amount      int    `json:"amount,omitempty"`
+}
+
+// Listener is a function which receives the events emitted by a game
+type Listener func(ev Event)
+
+// Subscribe registers a listener which will receive all events emitted by the game
+// from now on. Listeners are called synchronously, in subscription order,
+// so they must not call game methods which modify its state.
+func (g *Game) Subscribe(listener Listener) {
+	g.listeners = append(g.listeners, listener)
+}
+
+// Sends the passed event to all listeners
+func (g *Game) emit(ev Event) {
+	for _, listener := range g.listeners {
+		listener(ev)
+	}
+}
+
+// Returns an event of the passed type with no player or corporation set
+func newEvent(eventType string) Event {
+	return Event{
+		Type:        eventType,
+		Player:      -1,
+		Corporation: -1,
+	}
+}
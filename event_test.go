@@ -0,0 +1,62 @@
+package acquire
+
+import (
+	"testing"
+
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/mocks"
+)
+
+func TestFoundCorporationEmitsEvents(t *testing.T) {
+	players, optional := setup()
+	optional.StateMachine = &mocks.StateMachine{FakeStateName: interfaces.FoundCorpStateName, TimesCalled: map[string]int{}}
+	optional.Corporations[2].(*mocks.Corporation).FakeStock = 0
+	game, _ := New(players, optional)
+	game.currentPlayerNumber = 1
+	game.newCorpTiles = []interfaces.Tile{
+		&mocks.Tile{FakeNumber: 5, FakeLetter: "E"},
+		&mocks.Tile{FakeNumber: 6, FakeLetter: "E"},
+	}
+	events := []Event{}
+	game.Subscribe(func(ev Event) { events = append(events, ev) })
+
+	game.FoundCorporation(optional.Corporations[2])
+
+	expected := []Event{
+		{Type: CorporationFoundedEvent, Player: 1, Corporation: 2, Amount: 2},
+		{Type: FounderShareNotGivenEvent, Player: 1, Corporation: 2},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("Expected event %v, got %v", expected[i], events[i])
+		}
+	}
+}
+
+func TestMergeEmitsBonusPaidEvents(t *testing.T) {
+	players, optional := setup()
+	setupPlayTileMerge(optional.Corporations, optional.Board)
+	game, _ := New(players, optional)
+	game.currentPlayerNumber = 0
+
+	players[0].(*mocks.Player).FakeShares[optional.Corporations[0]] = 6
+	players[0].(*mocks.Player).FakeHasTile = true
+	players[2].(*mocks.Player).FakeShares[optional.Corporations[0]] = 4
+	optional.Corporations[0].(*mocks.Corporation).FakeMajorityBonus = 2000
+	optional.Corporations[0].(*mocks.Corporation).FakeMinorityBonus = 1000
+
+	bonuses := map[int]int{}
+	game.Subscribe(func(ev Event) {
+		if ev.Type == BonusPaidEvent && ev.Corporation == 0 {
+			bonuses[ev.Player] += ev.Amount
+		}
+	})
+	game.PlayTile(&mocks.Tile{FakeNumber: 6, FakeLetter: "E"})
+
+	if bonuses[0] != 2000 || bonuses[2] != 1000 || len(bonuses) != 2 {
+		t.Errorf("Expected bonus events of 2000$ for player 0 and 1000$ for player 2, got %v", bonuses)
+	}
+}
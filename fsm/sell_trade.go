@@ -14,6 +14,19 @@ func (s *sellTrade) Name() string {
 	return interfaces.SellTradeStateName
 }
 
+// ToSellTrade returns a SellTrade instance because it's an allowed state transition
+// (in a multiple merger, the next defunct corporation is dealt with)
+func (s *sellTrade) ToSellTrade() interfaces.State {
+	return &sellTrade{}
+}
+
+// ToUntieMerge returns a UntieMerge instance because it's an allowed state transition
+// (in a multiple merger, the mergemaker must choose which of the next defunct corporations
+// of the same size is dealt with first)
+func (s *sellTrade) ToUntieMerge() interfaces.State {
+	return &untieMerge{}
+}
+
 // ToBuyStock returns a BuyStock instance because it's an allowed state transition
 func (s *sellTrade) ToBuyStock() interfaces.State {
 	return &buyStock{}
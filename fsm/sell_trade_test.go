@@ -22,8 +22,15 @@ func TestSellTradeToFoundCorp(t *testing.T) {
 
 func TestSellTradeToUntieMerge(t *testing.T) {
 	state := &sellTrade{}
-	if state.ToUntieMerge().Name() != interfaces.ErrorStateName {
-		t.Errorf("Transition from SellTrade to UntieMerge must not be valid")
+	if state.ToUntieMerge().Name() != interfaces.UntieMergeStateName {
+		t.Errorf("Transition from SellTrade to UntieMerge must be valid")
+	}
+}
+
+func TestSellTradeToSellTrade(t *testing.T) {
+	state := &sellTrade{}
+	if state.ToSellTrade().Name() != interfaces.SellTradeStateName {
+		t.Errorf("Transition from SellTrade to SellTrade must be valid")
 	}
 }
 
@@ -19,6 +19,18 @@ func (s *untieMerge) ToSellTrade() interfaces.State {
 	return &sellTrade{}
 }
 
+// ToUntieMerge returns a UntieMerge instance because it's an allowed state transition
+// (after choosing the acquirer, defunct corporations of the same size may also need to be untied)
+func (s *untieMerge) ToUntieMerge() interfaces.State {
+	return &untieMerge{}
+}
+
+// ToBuyStock returns a BuyStock instance because it's an allowed state transition
+// (when no player owns stock shares of the defunct corporations)
+func (s *untieMerge) ToBuyStock() interfaces.State {
+	return &buyStock{}
+}
+
 // ToInsufficientPlayers returns an InsufficientPlayers instance because it's an allowed state transition
 func (s *untieMerge) ToInsufficientPlayers() interfaces.State {
 	return &insufficientPlayers{}
@@ -29,8 +29,15 @@ func TestUntieMergeToFoundCorp(t *testing.T) {
 
 func TestUntieMergeToBuyStock(t *testing.T) {
 	state := &untieMerge{}
-	if state.ToBuyStock().Name() != interfaces.ErrorStateName {
-		t.Errorf("Transition from UntieMerge to BuyStock must return not be valid")
+	if state.ToBuyStock().Name() != interfaces.BuyStockStateName {
+		t.Errorf("Transition from UntieMerge to BuyStock must be valid")
+	}
+}
+
+func TestUntieMergeToUntieMerge(t *testing.T) {
+	state := &untieMerge{}
+	if state.ToUntieMerge().Name() != interfaces.UntieMergeStateName {
+		t.Errorf("Transition from UntieMerge to UntieMerge must be valid")
 	}
 }
 
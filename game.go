@@ -13,6 +13,7 @@ import (
 	"github.com/svera/acquire/corporation"
 	"github.com/svera/acquire/fsm"
 	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/tile"
 	"github.com/svera/acquire/tileset"
 )
 
@@ -47,8 +48,15 @@ const (
 	TileNotOnHand = "tile_not_on_hand"
 	// NotAnAcquirerCorporation is an error returned when corporation is not the acquirer in a merge
 	NotAnAcquirerCorporation = "not_an_acquirer_corporation"
+	// NotATiedCorporation is an error returned when corporation is not one of the tied defunct corporations in a merge
+	NotATiedCorporation = "not_a_tied_corporation"
 	// TradeAmountNotEven is an error returned when number of stock shares is not even in a trade
 	TradeAmountNotEven = "trade_amount_not_even"
+	// NotCurrentDefunctCorporation is an error returned when trying to sell or trade stock shares of a corporation
+	// which is not the defunct one currently being dealt with in a merge
+	NotCurrentDefunctCorporation = "not_current_defunct_corporation"
+	// InvalidSharesAmount is an error returned when a negative amount of stock shares is passed
+	InvalidSharesAmount = "invalid_shares_amount"
 
 	totalCorporations      = 7
 	endGameCorporationSize = 41
@@ -75,47 +83,87 @@ type Game struct {
 	currentPlayerNumber int
 	initialPlayerNumber int
 	newCorpTiles        []interfaces.Tile
-	mergeCorps          map[string][]interfaces.Corporation
-	sellTradePlayers    []int
+	merge               *merge
 	lastPlayedTile      interfaces.Tile
 	round               int
 	isLastRound         bool
-	// When in sell_trade state, the current player is stored here temporary as the turn
-	// is passed to all defunct corporations stockholders
-	frozenPlayer int
+	seed                int64
+	rand                *rand.Rand
+	randSource          *countingSource
+	actions             []Action
+	listeners           []Listener
+	results             []Standing
+	bonusRounding       RoundingPolicy
+	openingTiles        []interfaces.Tile
+	discardedTiles      []interfaces.Tile
+	timeControl         TimeControl
+	timeUsed            []time.Duration
+	moveStartedAt       time.Time
+	fallbackRand        *rand.Rand
+	fallbackSource      *countingSource
+	hiddenAssets        bool
 }
 
 // New initialises a new Acquire game
 func New(players []interfaces.Player, optional Optional) (*Game, error) {
-	var err error
 	if len(players) < 3 || len(players) > 6 {
 		return nil, errors.New(WrongNumberPlayers)
 	}
-	if optional, err = initOptionalParameters(optional); err == nil {
-		gm := Game{
-			board:               optional.Board,
-			players:             players,
-			corporations:        optional.Corporations,
-			tileset:             optional.Tileset,
-			currentPlayerNumber: 0,
-			round:               1,
-			stateMachine:        optional.StateMachine,
-			isLastRound:         false,
-		}
-		for i := range gm.corporations {
-			gm.corporations[i].SetPricesChart(gm.setPricesChart(i))
-		}
-
-		for _, pl := range gm.players {
-			gm.giveInitialHand(pl)
-		}
-		gm.pickRandomPlayer()
-		return &gm, nil
+	gm, err := newGame(players, optional)
+	if err != nil {
+		return nil, err
 	}
-	return nil, err
+	gm.drawOpeningTiles()
+	for _, pl := range gm.players {
+		gm.giveInitialHand(pl)
+	}
+	return gm, nil
 }
 
-func initOptionalParameters(optional Optional) (Optional, error) {
+// Sets up a game with the passed players and optional elements, without dealing
+// any tile
+func newGame(players []interfaces.Player, optional Optional) (*Game, error) {
+	var err error
+	if optional.Seed == 0 {
+		optional.Seed = time.Now().UnixNano()
+	}
+	source := newCountingSource(optional.Seed)
+	fallbackSource := newFallbackSource(optional.Seed)
+	rn := rand.New(source)
+	if optional, err = initOptionalParameters(optional, rn); err != nil {
+		return nil, err
+	}
+	gm := &Game{
+		board:               optional.Board,
+		players:             players,
+		corporations:        optional.Corporations,
+		tileset:             optional.Tileset,
+		currentPlayerNumber: 0,
+		round:               1,
+		stateMachine:        optional.StateMachine,
+		isLastRound:         false,
+		seed:                optional.Seed,
+		rand:                rn,
+		randSource:          source,
+		bonusRounding:       optional.BonusRounding,
+		timeControl:         optional.TimeControl,
+		timeUsed:            make([]time.Duration, len(players)),
+		moveStartedAt:       optional.TimeControl.Clock.Now(),
+		fallbackRand:        rand.New(fallbackSource),
+		fallbackSource:      fallbackSource,
+		hiddenAssets:        optional.HiddenAssets,
+	}
+	for i := range gm.corporations {
+		gm.corporations[i].SetPricesChart(gm.setPricesChart(i))
+	}
+	return gm, nil
+}
+
+func initOptionalParameters(optional Optional, rn *rand.Rand) (Optional, error) {
+	var err error
+	if optional.TimeControl, err = initTimeControl(optional.TimeControl); err != nil {
+		return optional, err
+	}
 	if areCorporationsEmpty(optional.Corporations) {
 		optional.Corporations = defaultCorporations()
 	}
@@ -123,11 +171,14 @@ func initOptionalParameters(optional Optional) (Optional, error) {
 		optional.Board = board.New()
 	}
 	if optional.Tileset == nil {
-		optional.Tileset = tileset.New()
+		optional.Tileset = tileset.New(rn)
 	}
 	if optional.StateMachine == nil {
 		optional.StateMachine = fsm.New()
 	}
+	if optional.BonusRounding == nil {
+		optional.BonusRounding = RoundUpToHundred
+	}
 	return optional, nil
 }
 
@@ -140,11 +191,28 @@ func areCorporationsEmpty(corporations [7]interfaces.Corporation) bool {
 	return false
 }
 
+// Seed returns the seed used to initialise the game's random generator. Creating
+// a new game with the same seed and players yields the same tile order and starting player.
+func (g *Game) Seed() int64 {
+	return g.seed
+}
+
 // Corporations returns an array with all seven corporations
 func (g *Game) Corporations() [7]interfaces.Corporation {
 	return g.corporations
 }
 
+// Returns the position of the passed corporation in the corporations array,
+// or -1 if it does not belong to this game
+func (g *Game) corporationIndex(corp interfaces.Corporation) int {
+	for i := range g.corporations {
+		if g.corporations[i] == corp {
+			return i
+		}
+	}
+	return -1
+}
+
 // Initialises player hand of tiles
 func (g *Game) giveInitialHand(plyr interfaces.Player) {
 	for i := 0; i < 6; i++ {
@@ -192,7 +260,10 @@ func (g *Game) findCorporationsByActiveState(value bool) []interfaces.Corporatio
 // IsCorporationDefunct return true if the passed corporation is in a merge process
 // and will dissapear from the board after that merge is complete, false otherwise
 func (g *Game) IsCorporationDefunct(corp interfaces.Corporation) bool {
-	for _, defunct := range g.mergeCorps["defunct"] {
+	if g.merge == nil {
+		return false
+	}
+	for _, defunct := range g.merge.corps["defunct"] {
 		if corp == defunct {
 			return true
 		}
@@ -247,11 +318,17 @@ func (g *Game) isTileTemporaryUnplayable(tl interfaces.Tile) bool {
 	return true
 }
 
-// Player returns player with passed number
+// Player returns player with passed number, giving full access to their tiles, cash and
+// shares. Use ViewFor to get what other players are allowed to see of them.
 func (g *Game) Player(playerNumber int) interfaces.Player {
 	return g.players[playerNumber]
 }
 
+// NumberPlayers returns how many players are in the game, including deactivated ones
+func (g *Game) NumberPlayers() int {
+	return len(g.players)
+}
+
 // CurrentPlayer returns player currently in play
 func (g *Game) CurrentPlayer() interfaces.Player {
 	return g.players[g.currentPlayerNumber]
@@ -268,8 +345,12 @@ func (g *Game) PlayTile(tl interfaces.Tile) error {
 		return err
 	}
 
+	g.record(Action{Type: PlayTileAction, Player: g.currentPlayerNumber, Tile: tile.Coords(tl)})
 	g.CurrentPlayer().DiscardTile(tl)
 	g.lastPlayedTile = tl
+	ev := newEvent(TilePlacedEvent)
+	ev.Player, ev.Tile = g.currentPlayerNumber, tile.Coords(tl)
+	g.emit(ev)
 
 	if merge, mergeCorps := g.board.TileMergeCorporations(tl); merge {
 		g.startMerge(tl, mergeCorps)
@@ -333,37 +414,59 @@ func (g *Game) FoundCorporation(corp interfaces.Corporation) error {
 	if corp.IsActive() {
 		return errors.New(CorporationAlreadyOnBoard)
 	}
+	g.record(Action{Type: FoundCorporationAction, Player: g.currentPlayerNumber, Corporation: g.corporationIndex(corp)})
 	g.board.SetOwner(corp, g.newCorpTiles)
 	corp.Grow(len(g.newCorpTiles))
 	g.newCorpTiles = []interfaces.Tile{}
+	ev := newEvent(CorporationFoundedEvent)
+	ev.Player, ev.Corporation, ev.Amount = g.currentPlayerNumber, g.corporationIndex(corp), corp.Size()
+	g.emit(ev)
 	g.getFounderStockShare(g.CurrentPlayer(), corp)
 	g.stateMachine.ToBuyStock()
 	return nil
 }
 
 // Receive a free stock share from a recently founded corporation, if it has
-// remaining shares available
-// TODO this should trigger an event warning that no founder stock share will be given
-// of the founded corporation has no stock shares left
+// remaining shares available. Otherwise, an event warning that no founder
+// stock share is given is emitted.
 func (g *Game) getFounderStockShare(pl interfaces.Player, corp interfaces.Corporation) {
 	if corp.Stock() > 0 {
 		corp.RemoveStock(1)
 		pl.AddShares(corp, 1)
+		return
 	}
+	ev := newEvent(FounderShareNotGivenEvent)
+	ev.Player, ev.Corporation = g.playerNumber(pl), g.corporationIndex(corp)
+	g.emit(ev)
 }
 
 // Makes a corporation grow with the passed tiles
 func (g *Game) growCorporation(corp interfaces.Corporation, tiles []interfaces.Tile) {
 	g.board.SetOwner(corp, tiles)
 	corp.Grow(len(tiles))
+	g.emitCorporationGrown(corp)
+}
+
+func (g *Game) emitCorporationGrown(corp interfaces.Corporation) {
+	ev := newEvent(CorporationGrownEvent)
+	ev.Corporation, ev.Amount = g.corporationIndex(corp), corp.Size()
+	g.emit(ev)
 }
 
 // DeactivatePlayer gets the received player and marks it as inactive
 // because the player has left the game. All player's
 // assets are returned to its respective origin sets. If the deactivated player was in
-// his/her turn, turn passes to the next player.
+// his/her turn, turn passes to the next player, or to the next stockholder if
+// a merge is in progress. Players already inactive are left as they are.
 func (g *Game) DeactivatePlayer(pl interfaces.Player) {
+	if !pl.Active() {
+		return
+	}
+	g.record(Action{Type: DeactivatePlayerAction, Player: g.playerNumber(pl)})
 	pl.Deactivate()
+	ev := newEvent(PlayerDeactivatedEvent)
+	ev.Player = g.playerNumber(pl)
+	g.emit(ev)
 	pl.RemoveCash(pl.Cash())
 	g.tileset.Add(pl.Tiles())
 	for _, corp := range g.Corporations() {
@@ -376,11 +479,30 @@ func (g *Game) DeactivatePlayer(pl interfaces.Player) {
 		g.stateMachine.ToInsufficientPlayers()
 		return
 	}
-	for i := range g.players {
-		if g.players[i] == pl && g.currentPlayerNumber == i {
-			g.nextPlayer()
-			return
-		}
+	number := g.playerNumber(pl)
+	if g.merge != nil {
+		g.merge.removeSellTradePlayer(number)
+	}
+	if number == g.currentPlayerNumber {
+		g.passDeactivatedTurn()
+	}
+}
+
+// Moves the game on when the player in turn is deactivated. Pending decisions
+// are taken as if the player chose the simplest option: a tie is resolved with the first
+// tied corporation and a corporation about to be founded is not, leaving its tiles unincorporated.
+func (g *Game) passDeactivatedTurn() {
+	switch g.stateMachine.CurrentStateName() {
+	case interfaces.SellTradeStateName:
+		g.passSellTradeTurn()
+	case interfaces.UntieMergeStateName:
+		g.untieMerge(g.TiedCorps()[0])
+	case interfaces.FoundCorpStateName, interfaces.PlayTileStateName:
+		g.newCorpTiles = []interfaces.Tile{}
+		g.stateMachine.ToBuyStock()
+		g.nextPlayer()
+	default:
+		g.nextPlayer()
 	}
 }
 
@@ -403,7 +525,8 @@ func (g *Game) Round() int {
 // This can be done at any time. After announcing that the game is over,
 // the player may finish his/her turn.
 func (g *Game) ClaimEndGame() *Game {
-	if g.AreEndConditionsReached() {
+	if g.AreEndConditionsReached() && !g.isLastRound {
+		g.record(Action{Type: ClaimEndGameAction, Player: g.currentPlayerNumber})
 		g.isLastRound = true
 	}
 	return g
@@ -431,9 +554,54 @@ func (g *Game) nextPlayer() error {
 		g.stateMachine.ToPlayTile()
 	}
 	g.updateCurrentPlayerNumber()
+	return g.skipUnplayableTurns()
+}
+
+// A player who has no playable tiles does not place one in their turn, but can still
+// buy stock if there are active corporations. Otherwise, turn passes to the next player.
+// If no player is able to place a tile and no tiles remain to be drawn, the game ends.
+func (g *Game) skipUnplayableTurns() error {
+	for i := 0; !g.hasPlayableTiles(g.CurrentPlayer()); i++ {
+		if i == len(g.players) || g.isStalled() {
+			g.stateMachine.ToBuyStock()
+			g.stateMachine.ToEndGame()
+			return g.finish()
+		}
+		if g.existActiveCorporations() {
+			g.stateMachine.ToBuyStock()
+			return nil
+		}
+		if err := g.replaceUnplayableTiles(); err != nil {
+			return err
+		}
+		g.updateCurrentPlayerNumber()
+	}
 	return nil
 }
 
+// Returns true if the passed player has at least one tile which can be placed on board
+func (g *Game) hasPlayableTiles(pl interfaces.Player) bool {
+	for _, tl := range pl.Tiles() {
+		if g.IsTilePlayable(tl) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if there are no tiles left to draw and no active player has a playable tile
+func (g *Game) isStalled() bool {
+	if len(g.tileset.Tiles()) > 0 {
+		return false
+	}
+	for _, pl := range g.activePlayers() {
+		if g.hasPlayableTiles(pl) {
+			return false
+		}
+	}
+	return true
+}
+
 // Increases the number which specifies the current player.
 // Turn passes only to an active player.
 func (g *Game) updateCurrentPlayerNumber() {
@@ -446,6 +614,9 @@ func (g *Game) updateCurrentPlayerNumber() {
 			g.round++
 		}
 		if g.players[g.currentPlayerNumber].Active() {
+			ev := newEvent(TurnPassedEvent)
+			ev.Player = g.currentPlayerNumber
+			g.emit(ev)
 			return
 		}
 	}
@@ -458,12 +629,17 @@ func (g *Game) IsLastRound() bool {
 
 // A player takes a tile from the facedown cluster to replace
 // the one he/she played. This is not done until the end of
-// the turn.
+// the turn, and only if a tile was played.
 func (g *Game) drawTile() error {
 	var tile interfaces.Tile
 	var err error
-	if tile, err = g.tileset.Draw(); err == nil {
-		g.CurrentPlayer().PickTile(tile)
+	if !g.CurrentPlayer().Active() {
+		return nil
+	}
+	if len(g.CurrentPlayer().Tiles()) < 6 {
+		if tile, err = g.tileset.Draw(); err == nil {
+			g.CurrentPlayer().PickTile(tile)
+		}
 	}
 
 	if err = g.replaceUnplayableTiles(); err != nil {
@@ -478,9 +654,14 @@ func (g *Game) drawTile() error {
 // and draws an equal number of replacement tiles. This can
 // only be done once per turn.
 func (g *Game) replaceUnplayableTiles() error {
-	for _, tile := range g.CurrentPlayer().Tiles() {
-		if g.isTilePermanentlyUnplayable(tile) {
-			g.CurrentPlayer().DiscardTile(tile)
+	hand := append([]interfaces.Tile{}, g.CurrentPlayer().Tiles()...)
+	for _, tl := range hand {
+		if g.isTilePermanentlyUnplayable(tl) {
+			g.CurrentPlayer().DiscardTile(tl)
+			g.discardedTiles = append(g.discardedTiles, tl)
+			ev := newEvent(TileDiscardedEvent)
+			ev.Player, ev.Tile = g.currentPlayerNumber, tile.Coords(tl)
+			g.emit(ev)
 			if newTile, err := g.tileset.Draw(); err == nil {
 				g.CurrentPlayer().PickTile(newTile)
 			}
@@ -499,11 +680,45 @@ func defaultCorporations() [7]interfaces.Corporation {
 	return corporations
 }
 
-// Choose a random player who'll be the one to start playing
-func (g *Game) pickRandomPlayer() {
-	source := rand.NewSource(time.Now().UnixNano())
-	rn := rand.New(source)
-	numberPlayers := len(g.players)
-	g.currentPlayerNumber = rn.Intn(numberPlayers - 1)
-	g.initialPlayerNumber = g.currentPlayerNumber
+// Determines who goes first as the game rules state: "Each player draws a tile
+// from the facedown tiles and places it in its matching space on the gameboard.
+// The player who plays a tile closest to 1A goes first."
+func (g *Game) drawOpeningTiles() {
+	g.openingTiles = make([]interfaces.Tile, len(g.players))
+	g.initialPlayerNumber = 0
+	for i := range g.players {
+		tl, _ := g.tileset.Draw()
+		g.board.PutTile(tl)
+		g.openingTiles[i] = tl
+		if isCloserTo1A(tl, g.openingTiles[g.initialPlayerNumber]) {
+			g.initialPlayerNumber = i
+		}
+	}
+	g.currentPlayerNumber = g.initialPlayerNumber
+}
+
+// Returns true if tile t1 is closer to 1A than t2. Letters take precedence over
+// numbers, so 12A is closer to 1A than 1B.
+func isCloserTo1A(t1 interfaces.Tile, t2 interfaces.Tile) bool {
+	if t1.Letter() != t2.Letter() {
+		return t1.Letter() < t2.Letter()
+	}
+	return t1.Number() < t2.Number()
+}
+
+// OpeningTiles returns the tiles drawn by each player to determine who goes first,
+// indexed by player number
+func (g *Game) OpeningTiles() []interfaces.Tile {
+	return append([]interfaces.Tile{}, g.openingTiles...)
+}
+
+// DiscardedTiles returns the permanently unplayable tiles players have set aside,
+// in the order they were discarded
+func (g *Game) DiscardedTiles() []interfaces.Tile {
+	return append([]interfaces.Tile{}, g.discardedTiles...)
+}
+
+// InitialPlayerNumber returns the number of the player who started the game
+func (g *Game) InitialPlayerNumber() int {
+	return g.initialPlayerNumber
 }
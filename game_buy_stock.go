@@ -15,6 +15,7 @@ func (g *Game) BuyStock(buys map[interfaces.Corporation]int) error {
 	if err := g.checkBuy(buys); err != nil {
 		return err
 	}
+	g.record(Action{Type: BuyStockAction, Player: g.currentPlayerNumber, Buy: g.corporationsToIndexes(buys)})
 
 	for corp, amount := range buys {
 		g.buy(corp, amount)
@@ -24,15 +25,24 @@ func (g *Game) BuyStock(buys map[interfaces.Corporation]int) error {
 }
 
 func (g *Game) buy(corp interfaces.Corporation, amount int) {
+	if amount == 0 {
+		return
+	}
 	corp.RemoveStock(amount)
 	g.CurrentPlayer().
 		AddShares(corp, amount).
 		RemoveCash(corp.StockPrice() * amount)
+	ev := newEvent(SharesBoughtEvent)
+	ev.Player, ev.Corporation, ev.Shares, ev.Amount = g.currentPlayerNumber, g.corporationIndex(corp), amount, corp.StockPrice()*amount
+	g.emit(ev)
 }
 
 func (g *Game) checkBuy(buys map[interfaces.Corporation]int) error {
 	var totalStock, totalPrice int = 0, 0
 	for corp, amount := range buys {
+		if amount < 0 {
+			return errors.New(InvalidSharesAmount)
+		}
 		if corp.Size() == 0 {
 			return errors.New(StockSharesNotBuyable)
 		}
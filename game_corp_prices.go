@@ -46,3 +46,12 @@ func (g *Game) corpNumberToCategory(corpNumber int) int {
 	}
 	return 2
 }
+
+// Returns the current price and bonuses of the passed corporation
+func currentPrices(corp interfaces.Corporation) interfaces.Prices {
+	return interfaces.Prices{
+		Price:         corp.StockPrice(),
+		MajorityBonus: corp.MajorityBonus(),
+		MinorityBonus: corp.MinorityBonus(),
+	}
+}
@@ -13,13 +13,24 @@ func (g *Game) finish() error {
 	if g.stateMachine.CurrentStateName() != interfaces.EndGameStateName {
 		return errors.New(ActionNotAllowed)
 	}
+	bonuses := make([]int, len(g.players))
+	liquidations := make([][]Liquidation, len(g.players))
 	for _, corp := range g.activeCorporations() {
-		g.payBonuses(corp)
-		for _, pl := range g.players {
+		for number, amount := range g.payBonuses(corp, currentPrices(corp)) {
+			bonuses[number] += amount
+		}
+		for number, pl := range g.players {
 			if pl.Shares(corp) > 0 && pl.Active() {
-				g.sell(pl, corp, pl.Shares(corp))
+				liquidations[number] = append(liquidations[number], Liquidation{
+					Corporation: g.corporationIndex(corp),
+					Shares:      pl.Shares(corp),
+					Price:       corp.StockPrice(),
+				})
+				g.sell(pl, corp, pl.Shares(corp), corp.StockPrice())
 			}
 		}
 	}
+	g.results = g.rankResults(bonuses, liquidations)
+	g.emit(newEvent(GameEndedEvent))
 	return nil
 }
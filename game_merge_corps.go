@@ -7,39 +7,135 @@ import (
 	"github.com/svera/acquire/interfaces"
 )
 
+// Starts a merge. Defunct corporations are dealt with one at a time, from largest
+// to smallest, once the mergemaker has untied the acquirer if needed.
 func (g *Game) startMerge(tl interfaces.Tile, mergeCorps map[string][]interfaces.Corporation) {
 	g.board.PutTile(tl)
-	g.mergeCorps = mergeCorps
+	g.merge = newMerge(mergeCorps, g.currentPlayerNumber)
+	g.sortDefuncts()
+}
+
+// Sorts defunct corporations from largest to smallest and starts dealing with them,
+// unless the acquirer has to be untied first
+func (g *Game) sortDefuncts() {
 	if g.isMergeTied() {
 		g.stateMachine.ToUntieMerge()
-	} else {
-		for _, corp := range mergeCorps["defunct"] {
-			g.payBonuses(corp)
+		return
+	}
+	defuncts := g.merge.corps["defunct"]
+	sort.SliceStable(defuncts, func(i, j int) bool {
+		return defuncts[i].Size() > defuncts[j].Size()
+	})
+	g.merge.defunctIndex = 0
+	g.nextDefunct()
+}
+
+// Starts dealing with the next defunct corporation of the merge, unless the
+// mergemaker has to choose it among several of the same size first.
+// When all defunct corporations are dealt with, the merge is completed.
+// If the mergemaker has been deactivated meanwhile, ties are resolved taking
+// the first tied corporation and the turn passes to the next player once the merge is completed.
+func (g *Game) nextDefunct() {
+	mergemaker := g.merge.mergemaker
+	if g.merge.currentDefunct() == nil {
+		g.setCurrentPlayer(mergemaker)
+		g.completeMerge()
+		g.stateMachine.ToBuyStock()
+		if !g.players[mergemaker].Active() {
+			g.nextPlayer()
 		}
-		g.sellTradePlayers = g.setSellTradePlayers(mergeCorps["defunct"])
-		g.frozenPlayer = g.currentPlayerNumber
-		g.setCurrentPlayer(g.nextSellTradePlayer())
-		g.stateMachine.ToSellTrade()
+		return
+	}
+	if len(g.tiedDefuncts()) > 1 && g.players[mergemaker].Active() {
+		g.setCurrentPlayer(mergemaker)
+		g.stateMachine.ToUntieMerge()
+		return
+	}
+	g.resolveDefunct()
+}
+
+// Pays bonuses of the current defunct corporation at its pre-merger values and passes the turn to
+// its stockholders, starting with the mergemaker, so they can sell, trade or hold their shares.
+// If nobody owns shares of it, the next defunct corporation is dealt with.
+func (g *Game) resolveDefunct() {
+	defunct := g.merge.currentDefunct()
+	g.payBonuses(defunct, g.merge.pricesOf(defunct))
+	g.merge.sellTradePlayers = g.setSellTradePlayers([]interfaces.Corporation{defunct}, g.merge.mergemaker)
+	if len(g.merge.sellTradePlayers) == 0 {
+		g.merge.defunctIndex++
+		g.nextDefunct()
+		return
 	}
+	g.setCurrentPlayer(g.nextSellTradePlayer())
+	g.stateMachine.ToSellTrade()
+}
+
+// Returns the defunct corporations not dealt with yet which have the same
+// size as the largest of them
+func (g *Game) tiedDefuncts() []interfaces.Corporation {
+	pending := g.merge.corps["defunct"][g.merge.defunctIndex:]
+	tied := []interfaces.Corporation{}
+	for _, corp := range pending {
+		if corp.Size() == pending[0].Size() {
+			tied = append(tied, corp)
+		}
+	}
+	return tied
+}
+
+// CurrentDefunct returns the defunct corporation whose stockholders are currently
+// selling, trading or holding their shares, or nil if there is none
+func (g *Game) CurrentDefunct() interfaces.Corporation {
+	if g.stateMachine.CurrentStateName() != interfaces.SellTradeStateName || g.merge == nil {
+		return nil
+	}
+	return g.merge.currentDefunct()
+}
+
+// Acquirer returns the corporation which survives the current merge,
+// or nil if there is no merge in progress or the acquirer is still tied
+func (g *Game) Acquirer() interfaces.Corporation {
+	if g.merge == nil {
+		return nil
+	}
+	return g.merge.acquirer()
+}
+
+// Pays bonuses to owners of stock of a corporation using the passed bonus values,
+// returning the amounts paid indexed by player number
+func (g *Game) payBonuses(corp interfaces.Corporation, prices interfaces.Prices) []int {
+	amounts := g.bonuses(corp, prices)
+	for number, amount := range amounts {
+		if amount > 0 {
+			g.players[number].AddCash(amount)
+			ev := newEvent(BonusPaidEvent)
+			ev.Player, ev.Corporation, ev.Amount = number, g.corporationIndex(corp), amount
+			g.emit(ev)
+		}
+	}
+	return amounts
 }
 
 // Calculates and returns bonus amounts to be paid to owners of stock of a
-// corporation
-func (g *Game) payBonuses(corp interfaces.Corporation) {
+// corporation with the passed bonus values, indexed by player number.
+// Every amount is rounded following the game's rounding policy.
+func (g *Game) bonuses(corp interfaces.Corporation, prices interfaces.Prices) []int {
+	amounts := make([]int, len(g.players))
 	stockHolders := g.getMainStockHolders(corp)
 	numberMajorityHolders := len(stockHolders["majority"])
 	numberMinorityHolders := len(stockHolders["minority"])
 
 	for _, majorityStockHolder := range stockHolders["majority"] {
 		if numberMajorityHolders > 1 {
-			majorityStockHolder.AddCash((corp.MajorityBonus() + corp.MinorityBonus()) / numberMajorityHolders)
+			amounts[g.playerNumber(majorityStockHolder)] += g.bonusRounding((prices.MajorityBonus + prices.MinorityBonus) / numberMajorityHolders)
 		} else {
-			majorityStockHolder.AddCash(corp.MajorityBonus() / numberMajorityHolders)
+			amounts[g.playerNumber(majorityStockHolder)] += g.bonusRounding(prices.MajorityBonus)
 		}
 	}
 	for _, minorityStockHolder := range stockHolders["minority"] {
-		minorityStockHolder.AddCash(corp.MinorityBonus() / numberMinorityHolders)
+		amounts[g.playerNumber(minorityStockHolder)] += g.bonusRounding(prices.MinorityBonus / numberMinorityHolders)
 	}
+	return amounts
 }
 
 // Taken from the game rules:
@@ -69,10 +165,10 @@ func (g *Game) getMainStockHolders(corp interfaces.Corporation) map[string][]int
 }
 
 // Returns players who are shareholders of at least one of the passed companies
-// starting from the current one in play (mergemaker)
-func (g *Game) setSellTradePlayers(sellableCorps []interfaces.Corporation) []int {
+// starting from the passed one (mergemaker)
+func (g *Game) setSellTradePlayers(sellableCorps []interfaces.Corporation, start int) []int {
 	shareholders := []int{}
-	index := g.currentPlayerNumber
+	index := start
 	for _ = range g.players {
 		for _, corp := range sellableCorps {
 			if g.players[index].Shares(corp) > 0 && g.players[index].Active() {
@@ -121,51 +217,91 @@ func stockHoldersWithSameAmount(start int, stockHolders []interfaces.Player, cor
 // Checks if two ore more corps are tied for be the acquirer in a merge
 // whichs needs the merger player to decide which one would get that role.
 func (g *Game) isMergeTied() bool {
-	if len(g.mergeCorps["acquirer"]) > 1 {
-		return true
-	}
-	return false
+	return g.merge != nil && len(g.merge.corps["acquirer"]) > 1
 }
 
-// TiedCorps returns all corporations that are tied in a merge
+// TiedCorps returns all corporations that are tied in a merge, either to be the acquirer
+// or, in a multiple merger, to be the next defunct corporation to be dealt with
 func (g *Game) TiedCorps() []interfaces.Corporation {
 	corps := []interfaces.Corporation{}
+	if g.stateMachine.CurrentStateName() != interfaces.UntieMergeStateName || g.merge == nil {
+		return corps
+	}
 	if g.isMergeTied() {
-		corps = g.mergeCorps["acquirer"]
+		corps = g.merge.corps["acquirer"]
+	} else if g.merge.currentDefunct() != nil {
+		corps = g.tiedDefuncts()
 	}
 	return corps
 }
 
-// UntieMerge resolves a tied merge selecting which corporation will be the acquirer,
-// marking the rest as defunct
-func (g *Game) UntieMerge(acquirer interfaces.Corporation) error {
-	if g.stateMachine.CurrentStateName() != interfaces.UntieMergeStateName {
+// UntieMerge resolves a tied merge. If corporations are tied to be the acquirer, the passed one
+// is selected as such, marking the rest as defunct. If, in a multiple merger, defunct corporations
+// are tied, the passed one is the next to be dealt with.
+func (g *Game) UntieMerge(corp interfaces.Corporation) error {
+	if g.stateMachine.CurrentStateName() != interfaces.UntieMergeStateName || g.merge == nil {
 		return errors.New(ActionNotAllowed)
 	}
-	for i, corp := range g.mergeCorps["acquirer"] {
+	if !containsCorporation(g.TiedCorps(), corp) {
+		if g.isMergeTied() {
+			return errors.New(NotAnAcquirerCorporation)
+		}
+		return errors.New(NotATiedCorporation)
+	}
+	g.record(Action{Type: UntieMergeAction, Player: g.currentPlayerNumber, Corporation: g.corporationIndex(corp)})
+	g.untieMerge(corp)
+	return nil
+}
+
+// Resolves the tied merge with the passed corporation, which must be one of the tied ones
+func (g *Game) untieMerge(corp interfaces.Corporation) {
+	if g.isMergeTied() {
+		g.untieAcquirer(corp)
+		return
+	}
+	g.untieDefuncts(corp)
+}
+
+// Selects which of the defunct corporations of the same size is dealt with first
+func (g *Game) untieDefuncts(defunct interfaces.Corporation) {
+	defuncts := g.merge.corps["defunct"]
+	current := g.merge.defunctIndex
+	for i, corp := range defuncts[current:] {
+		if corp == defunct {
+			defuncts[current], defuncts[current+i] = defuncts[current+i], defuncts[current]
+			g.resolveDefunct()
+			return
+		}
+	}
+}
+
+// Selects which of the corporations of the same size is the acquirer in a merge
+func (g *Game) untieAcquirer(acquirer interfaces.Corporation) {
+	for i, corp := range g.merge.corps["acquirer"] {
 		if corp == acquirer {
-			g.mergeCorps["defunct"] = append(
-				g.mergeCorps["defunct"],
-				append(g.mergeCorps["acquirer"][:i], g.mergeCorps["acquirer"][i+1:]...)...,
+			g.merge.corps["defunct"] = append(
+				g.merge.corps["defunct"],
+				append(g.merge.corps["acquirer"][:i], g.merge.corps["acquirer"][i+1:]...)...,
 			)
-			g.mergeCorps["acquirer"] = []interfaces.Corporation{corp}
-			g.startMerge(g.lastPlayedTile, g.mergeCorps)
-			return nil
+			g.merge.corps["acquirer"] = []interfaces.Corporation{corp}
+			g.sortDefuncts()
+			return
 		}
 	}
-
-	return errors.New(NotAnAcquirerCorporation)
 }
 
 // Adds tiles from the defunct corporations to the acquirer one
 // and set acquirer as owner of those tiles on board. Finally, resets
 // merge information
 func (g *Game) completeMerge() {
-	acquirer := g.mergeCorps["acquirer"][0]
-	for _, defunct := range g.mergeCorps["defunct"] {
+	acquirer := g.merge.acquirer()
+	for _, defunct := range g.merge.corps["defunct"] {
 		acquirer.Grow(defunct.Size())
 		defunct.Reset()
 		g.board.ChangeOwner(defunct, acquirer)
+		ev := newEvent(CorporationDefunctEvent)
+		ev.Corporation = g.corporationIndex(defunct)
+		g.emit(ev)
 	}
 	g.board.SetOwner(acquirer, []interfaces.Tile{g.lastPlayedTile})
 	acquirer.Grow(1)
@@ -176,5 +312,15 @@ func (g *Game) completeMerge() {
 			g.board.SetOwner(acquirer, []interfaces.Tile{cell.(interfaces.Tile)})
 		}
 	}
-	g.mergeCorps = map[string][]interfaces.Corporation{}
+	g.emitCorporationGrown(acquirer)
+	g.merge = nil
+}
+
+func containsCorporation(corps []interfaces.Corporation, corp interfaces.Corporation) bool {
+	for _, c := range corps {
+		if c == corp {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,243 @@
+package acquire
+
+import (
+	"testing"
+
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/mocks"
+	"github.com/svera/acquire/tile"
+)
+
+// Testing this multiple merger, in which corporation 2 (size 4) acquires
+// corporations 0 (size 3) and 1 (size 2):
+//
+//	   2 3 4 5 6 7 8 9
+//	C          1
+//	D          1
+//	E [2][2][2][2]><[0][0][0]
+func TestMultipleMergerDealsWithDefunctsOneAtATime(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 1})
+	corps := game.Corporations()
+	putCorporation(game, corps[2], "2E", "3E", "4E", "5E")
+	putCorporation(game, corps[0], "7E", "8E", "9E")
+	putCorporation(game, corps[1], "6C", "6D")
+	game.currentPlayerNumber = 0
+	mergeTile := tile.New(6, "E")
+	game.Player(0).PickTile(mergeTile)
+	game.Player(0).AddShares(corps[0], 1).AddShares(corps[1], 1)
+	game.Player(1).AddShares(corps[0], 2)
+	game.Player(2).AddShares(corps[1], 3)
+
+	if err := game.PlayTile(mergeTile); err != nil {
+		t.Fatalf("Merge tile must be playable, got error %s", err)
+	}
+	if game.CurrentDefunct() != corps[0] {
+		t.Fatalf("Largest defunct corporation must be dealt with first")
+	}
+	if game.Player(1).Cash() != 9000 || game.Player(2).Cash() != 6000 {
+		t.Errorf("Only bonuses of the first defunct corporation must be paid, got %d$ and %d$", game.Player(1).Cash(), game.Player(2).Cash())
+	}
+	expectedTurns := []int{0, 1}
+	for _, expected := range expectedTurns {
+		if game.CurrentPlayerNumber() != expected {
+			t.Fatalf("Expected player %d to sell or trade, got %d", expected, game.CurrentPlayerNumber())
+		}
+		game.SellTrade(map[interfaces.Corporation]int{}, map[interfaces.Corporation]int{})
+	}
+
+	if game.GameStateName() != interfaces.SellTradeStateName || game.CurrentDefunct() != corps[1] {
+		t.Fatalf("Second defunct corporation must be dealt with after the first one")
+	}
+	if game.Player(2).Cash() != 8000 {
+		t.Errorf("Bonuses of the second defunct corporation must be paid when it is dealt with, expected %d$, got %d$", 8000, game.Player(2).Cash())
+	}
+	expectedTurns = []int{0, 2}
+	for _, expected := range expectedTurns {
+		if game.CurrentPlayerNumber() != expected {
+			t.Fatalf("Expected player %d to sell or trade, got %d", expected, game.CurrentPlayerNumber())
+		}
+		game.SellTrade(map[interfaces.Corporation]int{}, map[interfaces.Corporation]int{})
+	}
+
+	if game.GameStateName() != interfaces.BuyStockStateName {
+		t.Errorf("Game must change its state to BuyStock after all defunct corporations are dealt with, got %s", game.GameStateName())
+	}
+	if game.CurrentPlayerNumber() != 0 {
+		t.Errorf("Turn must return to the mergemaker, got player %d", game.CurrentPlayerNumber())
+	}
+	if corps[2].Size() != 10 || corps[0].Size() != 0 || corps[1].Size() != 0 {
+		t.Errorf("Acquirer must absorb all defunct corporations, got sizes %d, %d and %d", corps[2].Size(), corps[0].Size(), corps[1].Size())
+	}
+}
+
+// Testing a merger in which nobody owns stock shares of the defunct corporation
+func TestMergeWithoutDefunctStockholders(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 1})
+	corps := game.Corporations()
+	putCorporation(game, corps[2], "2E", "3E", "4E", "5E")
+	putCorporation(game, corps[0], "7E", "8E")
+	game.currentPlayerNumber = 0
+	mergeTile := tile.New(6, "E")
+	game.Player(0).PickTile(mergeTile)
+
+	game.PlayTile(mergeTile)
+
+	if game.GameStateName() != interfaces.BuyStockStateName {
+		t.Errorf("Game must change its state to BuyStock, got %s", game.GameStateName())
+	}
+	if corps[2].Size() != 7 {
+		t.Errorf("Acquirer must absorb the defunct corporation, expected size %d, got %d", 7, corps[2].Size())
+	}
+}
+
+// Puts the tiles with the passed coordinates on board as owned by the passed corporation
+func putCorporation(game *Game, corp interfaces.Corporation, coords ...string) {
+	tiles, _ := coordsToTiles(coords)
+	for _, tl := range tiles {
+		game.board.PutTile(tl)
+	}
+	game.board.SetOwner(corp, tiles)
+	corp.Grow(len(tiles))
+}
+
+// Defunct stock must be sold at the price the corporation had when the merging tile was
+// placed, even if the corporation reports a different one afterwards
+func TestSellTradeUsesPreMergerPrice(t *testing.T) {
+	players, optional := setup()
+	setupPlayTileMerge(optional.Corporations, optional.Board)
+	tileToPlay := &mocks.Tile{FakeNumber: 6, FakeLetter: "E"}
+	game, _ := New(players, optional)
+	game.currentPlayerNumber = 0
+	players[0].(*mocks.Player).FakeShares[optional.Corporations[0]] = 6
+	players[0].(*mocks.Player).FakeHasTile = true
+	optional.Corporations[0].(*mocks.Corporation).FakeMajorityBonus = 2000
+	optional.Corporations[0].(*mocks.Corporation).FakeMinorityBonus = 1000
+	optional.Corporations[0].(*mocks.Corporation).FakeStockPrice = 200
+
+	game.PlayTile(tileToPlay)
+	optional.Corporations[0].(*mocks.Corporation).FakeStockPrice = 1000
+	game.stateMachine.(*mocks.StateMachine).FakeStateName = interfaces.SellTradeStateName
+	game.SellTrade(map[interfaces.Corporation]int{optional.Corporations[0]: 6}, map[interfaces.Corporation]int{})
+
+	// 6000$ (base cash) + 3000 (bonuses) + (200 * 6) (6 shares sold at pre-merger price) = 10200$
+	if players[0].Cash() != 10200 {
+		t.Errorf("Defunct shares must be sold at pre-merger price, expected %d$, got %d$", 10200, players[0].Cash())
+	}
+}
+
+// Trading is limited by the acquirer stock, not the defunct one
+func TestTradeChecksAcquirerStock(t *testing.T) {
+	players, optional := setup()
+	optional.StateMachine = &mocks.StateMachine{FakeStateName: interfaces.SellTradeStateName, TimesCalled: map[string]int{}}
+	game, _ := New(players, optional)
+	game.merge = newMerge(map[string][]interfaces.Corporation{
+		"acquirer": {optional.Corporations[1]},
+		"defunct":  {optional.Corporations[0]},
+	}, 0)
+	game.merge.sellTradePlayers = []int{1}
+	players[0].(*mocks.Player).FakeShares[optional.Corporations[0]] = 6
+	optional.Corporations[0].RemoveStock(25)
+	optional.Corporations[1].RemoveStock(23)
+
+	if _, err := game.SellTrade(map[interfaces.Corporation]int{}, map[interfaces.Corporation]int{optional.Corporations[0]: 6}); err == nil || err.Error() != NotEnoughStockShares {
+		t.Errorf("Trade must not be allowed when the acquirer has not enough stock left")
+	}
+	if _, err := game.SellTrade(map[interfaces.Corporation]int{}, map[interfaces.Corporation]int{optional.Corporations[0]: 4}); err != nil {
+		t.Errorf("Trade must be allowed while the acquirer has enough stock, got error %s", err)
+	}
+}
+
+// Sets up a merge in which corporation 0 is the defunct one and corporation 1 the acquirer,
+// with player 0 owning 6 shares of the defunct and 2 of another corporation
+func setupSellTrade() (*Game, []interfaces.Player, Optional) {
+	players, optional := setup()
+	optional.StateMachine = &mocks.StateMachine{FakeStateName: interfaces.SellTradeStateName, TimesCalled: map[string]int{}}
+	optional.Corporations[0].(*mocks.Corporation).FakeStockPrice = 300
+	game, _ := New(players, optional)
+	game.merge = newMerge(map[string][]interfaces.Corporation{
+		"acquirer": {optional.Corporations[1]},
+		"defunct":  {optional.Corporations[0]},
+	}, 0)
+	game.merge.sellTradePlayers = []int{1}
+	players[0].(*mocks.Player).FakeShares[optional.Corporations[0]] = 6
+	players[0].(*mocks.Player).FakeShares[optional.Corporations[2]] = 2
+	return game, players, optional
+}
+
+func TestSellTradeOnlyCurrentDefunct(t *testing.T) {
+	game, _, optional := setupSellTrade()
+	other := optional.Corporations[2]
+
+	if _, err := game.SellTrade(map[interfaces.Corporation]int{other: 2}, map[interfaces.Corporation]int{}); err == nil || err.Error() != NotCurrentDefunctCorporation {
+		t.Errorf("Selling shares of a corporation which is not the current defunct must fail with error %s, got %v", NotCurrentDefunctCorporation, err)
+	}
+	if _, err := game.SellTrade(map[interfaces.Corporation]int{}, map[interfaces.Corporation]int{other: 2}); err == nil || err.Error() != NotCurrentDefunctCorporation {
+		t.Errorf("Trading shares of a corporation which is not the current defunct must fail with error %s, got %v", NotCurrentDefunctCorporation, err)
+	}
+	if _, err := game.SellTrade(map[interfaces.Corporation]int{optional.Corporations[0]: -2}, map[interfaces.Corporation]int{}); err == nil || err.Error() != InvalidSharesAmount {
+		t.Errorf("Selling a negative amount of shares must fail with error %s, got %v", InvalidSharesAmount, err)
+	}
+	if _, err := game.SellTrade(map[interfaces.Corporation]int{other: 0}, map[interfaces.Corporation]int{}); err != nil {
+		t.Errorf("Zero amounts of other corporations must be allowed, got error %s", err)
+	}
+}
+
+func TestSellTradeTotalExceedsOwned(t *testing.T) {
+	game, _, optional := setupSellTrade()
+	defunct := optional.Corporations[0]
+
+	if _, err := game.SellTrade(map[interfaces.Corporation]int{defunct: 4}, map[interfaces.Corporation]int{defunct: 4}); err == nil || err.Error() != NotEnoughCorporationSharesOwned {
+		t.Errorf("Selling and trading more shares than owned must fail with error %s, got %v", NotEnoughCorporationSharesOwned, err)
+	}
+}
+
+func TestSellTradeResult(t *testing.T) {
+	game, players, optional := setupSellTrade()
+	defunct := optional.Corporations[0]
+
+	result, err := game.SellTrade(map[interfaces.Corporation]int{defunct: 1}, map[interfaces.Corporation]int{defunct: 4})
+	if err != nil {
+		t.Fatalf("Selling and trading owned shares must be allowed, got error %s", err)
+	}
+	expected := SellTradeResult{Player: 0, Corporation: 0, Held: 1, Sold: 1, Traded: 4, Cash: 300, AcquirerShares: 2}
+	if result != expected {
+		t.Errorf("Expected result %+v, got %+v", expected, result)
+	}
+	if players[0].Shares(defunct) != 1 || players[0].Shares(optional.Corporations[1]) != 2 {
+		t.Errorf("Player must hold %d shares of the defunct and %d of the acquirer, got %d and %d", 1, 2, players[0].Shares(defunct), players[0].Shares(optional.Corporations[1]))
+	}
+}
+
+// Testing the multiple merger of TestMultipleMergerDealsWithDefunctsOneAtATime, with the
+// mergemaker being deactivated while selling or trading
+func TestDeactivateMergemakerWhileSellingTrading(t *testing.T) {
+	game, _ := New(newDefaultPlayers(4), Optional{Seed: 1})
+	corps := game.Corporations()
+	putCorporation(game, corps[2], "2E", "3E", "4E", "5E")
+	putCorporation(game, corps[0], "7E", "8E", "9E")
+	putCorporation(game, corps[1], "6C", "6D")
+	game.currentPlayerNumber = 0
+	mergeTile := tile.New(6, "E")
+	game.Player(0).PickTile(mergeTile)
+	game.Player(0).AddShares(corps[0], 1).AddShares(corps[1], 1)
+	game.Player(1).AddShares(corps[0], 2)
+	game.Player(2).AddShares(corps[1], 3)
+	game.PlayTile(mergeTile)
+
+	game.DeactivatePlayer(game.Player(0))
+	if game.GameStateName() != interfaces.SellTradeStateName || game.CurrentPlayerNumber() != 1 {
+		t.Fatalf("Turn to sell or trade must pass to the next stockholder, got player %d in state %s", game.CurrentPlayerNumber(), game.GameStateName())
+	}
+	game.SellTrade(map[interfaces.Corporation]int{}, map[interfaces.Corporation]int{})
+	if game.CurrentDefunct() != corps[1] || game.CurrentPlayerNumber() != 2 {
+		t.Fatalf("Deactivated player must not be asked to sell or trade, expected player %d, got %d", 2, game.CurrentPlayerNumber())
+	}
+	game.SellTrade(map[interfaces.Corporation]int{}, map[interfaces.Corporation]int{})
+
+	if game.GameStateName() != interfaces.PlayTileStateName || game.CurrentPlayerNumber() != 1 {
+		t.Errorf("Turn must pass to the player after the deactivated mergemaker once the merge is completed, got player %d in state %s", game.CurrentPlayerNumber(), game.GameStateName())
+	}
+	if corps[2].Size() != 10 {
+		t.Errorf("Acquirer must absorb all defunct corporations, got size %d", corps[2].Size())
+	}
+}
@@ -6,82 +6,134 @@ import (
 	"github.com/svera/acquire/interfaces"
 )
 
-// SellTrade sells and trades stock shares from defunct corporations
-func (g *Game) SellTrade(sell map[interfaces.Corporation]int, trade map[interfaces.Corporation]int) error {
+// SellTradeResult describes what the current player did with their stock shares
+// of the defunct corporation being dealt with in a merge.
+// Corporation is the index of the defunct corporation in the corporations array.
+type SellTradeResult struct {
+	Player      int `json:"player"`
+	Corporation int `json:"corporation"`
+	// Held is the number of shares kept by the player, the ones neither sold nor traded
+	Held   int `json:"held"`
+	Sold   int `json:"sold"`
+	Traded int `json:"traded"`
+	// Cash is the money received for the sold shares
+	Cash int `json:"cash"`
+	// AcquirerShares is the number of shares of the acquirer corporation received for the traded ones
+	AcquirerShares int `json:"acquirerShares"`
+}
+
+// SellTrade sells and trades stock shares of the current defunct corporation,
+// holding the rest of them. Only the current defunct corporation can appear in the passed
+// maps with amounts other than zero, and the player cannot sell and trade more shares of it
+// than they own. Shares are sold at the price the corporation had before the merger.
+func (g *Game) SellTrade(sell map[interfaces.Corporation]int, trade map[interfaces.Corporation]int) (SellTradeResult, error) {
 	if err := g.checkSellTrade(sell, trade); err != nil {
-		return err
+		return SellTradeResult{}, err
 	}
-	for corp, amount := range sell {
-		g.sell(g.CurrentPlayer(), corp, amount)
+	g.record(Action{
+		Type:   SellTradeAction,
+		Player: g.currentPlayerNumber,
+		Sell:   g.corporationsToIndexes(sell),
+		Trade:  g.corporationsToIndexes(trade),
+	})
+	defunct := g.merge.currentDefunct()
+	pl := g.CurrentPlayer()
+	price := g.merge.pricesOf(defunct).Price
+	result := SellTradeResult{
+		Player:         g.currentPlayerNumber,
+		Corporation:    g.corporationIndex(defunct),
+		Held:           pl.Shares(defunct) - sell[defunct] - trade[defunct],
+		Sold:           sell[defunct],
+		Traded:         trade[defunct],
+		Cash:           sell[defunct] * price,
+		AcquirerShares: trade[defunct] / 2,
 	}
+	g.sell(pl, defunct, result.Sold, price)
+	g.trade(defunct, result.Traded)
+	g.passSellTradeTurn()
+	return result, nil
+}
 
-	for corp, amount := range trade {
-		g.trade(corp, amount)
-	}
-	if len(g.sellTradePlayers) == 0 {
-		g.setCurrentPlayer(g.frozenPlayer)
-		g.completeMerge()
-		g.stateMachine.ToBuyStock()
+// Passes the turn to the next stockholder of the current defunct corporation, or deals with
+// the next defunct corporation if there are no stockholders left
+func (g *Game) passSellTradeTurn() {
+	if len(g.merge.sellTradePlayers) == 0 {
+		g.merge.defunctIndex++
+		g.nextDefunct()
 	} else {
 		g.setCurrentPlayer(g.nextSellTradePlayer())
 	}
-	return nil
 }
 
 // Extract the number of the next player to sell or trade stock shares from
 // the merge's defunct corps list of stockholders
 func (g *Game) nextSellTradePlayer() int {
-	pl := g.sellTradePlayers[0]
-	g.sellTradePlayers = append(g.sellTradePlayers[:0], g.sellTradePlayers[1:]...)
+	pl := g.merge.sellTradePlayers[0]
+	g.merge.sellTradePlayers = append(g.merge.sellTradePlayers[:0], g.merge.sellTradePlayers[1:]...)
 	return pl
 }
 
-// Sells owned shares of a defunct corporation, returning them to the
+// Sells owned shares of a corporation at the passed price per share, returning them to the
 // corporation's stock
-func (g *Game) sell(pl interfaces.Player, corp interfaces.Corporation, amount int) {
+func (g *Game) sell(pl interfaces.Player, corp interfaces.Corporation, amount int, price int) {
+	if amount == 0 {
+		return
+	}
 	corp.AddStock(amount)
 	pl.RemoveShares(corp, amount).
-		AddCash(corp.StockPrice() * amount)
+		AddCash(price * amount)
+	ev := newEvent(SharesSoldEvent)
+	ev.Player, ev.Corporation, ev.Shares, ev.Amount = g.playerNumber(pl), g.corporationIndex(corp), amount, price*amount
+	g.emit(ev)
 }
 
 // Trades two stock shares from a defunct corporation for a
 // share of the acquiring one
 func (g *Game) trade(corp interfaces.Corporation, amount int) {
-	acquirer := g.mergeCorps["acquirer"][0]
+	if amount == 0 {
+		return
+	}
+	acquirer := g.merge.acquirer()
 	amountSharesAcquiringCorp := amount / 2
 	corp.AddStock(amount)
 	acquirer.RemoveStock(amountSharesAcquiringCorp)
 	g.CurrentPlayer().
 		RemoveShares(corp, amount).
 		AddShares(acquirer, amountSharesAcquiringCorp)
+	ev := newEvent(SharesTradedEvent)
+	ev.Player, ev.Corporation, ev.Shares, ev.Amount = g.currentPlayerNumber, g.corporationIndex(corp), amount, amountSharesAcquiringCorp
+	g.emit(ev)
 }
 
 // Check that the requisites for both selling and trading stock shares are met
 func (g *Game) checkSellTrade(sell map[interfaces.Corporation]int, trade map[interfaces.Corporation]int) error {
-	if g.stateMachine.CurrentStateName() != interfaces.SellTradeStateName {
+	if g.stateMachine.CurrentStateName() != interfaces.SellTradeStateName || g.merge == nil || g.merge.currentDefunct() == nil {
 		return errors.New(ActionNotAllowed)
 	}
-	for corp, amount := range sell {
-		if amount > 0 && g.CurrentPlayer().Shares(corp) == 0 {
-			return errors.New(NoCorporationSharesOwned)
-		}
-		if g.CurrentPlayer().Shares(corp) < amount {
-			return errors.New(NotEnoughCorporationSharesOwned)
+	defunct := g.merge.currentDefunct()
+	for _, amounts := range []map[interfaces.Corporation]int{sell, trade} {
+		for corp, amount := range amounts {
+			if amount < 0 {
+				return errors.New(InvalidSharesAmount)
+			}
+			if amount > 0 && corp != defunct {
+				return errors.New(NotCurrentDefunctCorporation)
+			}
 		}
 	}
-	for corp, amount := range trade {
-		if amount > 0 && g.CurrentPlayer().Shares(corp) == 0 {
-			return errors.New(NoCorporationSharesOwned)
-		}
-		if amount%2 != 0 {
-			return errors.New(TradeAmountNotEven)
-		}
-		if corp.Stock() < (amount / 2) {
-			return errors.New(NotEnoughStockShares)
-		}
-		if g.CurrentPlayer().Shares(corp) < amount {
-			return errors.New(NotEnoughCorporationSharesOwned)
-		}
+	owned := g.CurrentPlayer().Shares(defunct)
+	total := sell[defunct] + trade[defunct]
+	if total > 0 && owned == 0 {
+		return errors.New(NoCorporationSharesOwned)
+	}
+	if trade[defunct]%2 != 0 {
+		return errors.New(TradeAmountNotEven)
+	}
+	if g.merge.acquirer().Stock() < trade[defunct]/2 {
+		return errors.New(NotEnoughStockShares)
+	}
+	if total > owned {
+		return errors.New(NotEnoughCorporationSharesOwned)
 	}
 	return nil
 }
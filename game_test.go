@@ -3,8 +3,11 @@ package acquire
 import (
 	"testing"
 
+	"github.com/svera/acquire/board"
 	"github.com/svera/acquire/interfaces"
 	"github.com/svera/acquire/mocks"
+	"github.com/svera/acquire/tile"
+	"github.com/svera/acquire/tileset"
 )
 
 func TestNewGameWrongNumberPlayers(t *testing.T) {
@@ -27,6 +30,50 @@ func TestNewGameInitsPlayersTilesets(t *testing.T) {
 	}
 }
 
+func TestNewGamePicksPlayerWithTileClosestTo1A(t *testing.T) {
+	players, _ := setup()
+	openingTiles := []interfaces.Tile{tile.New(3, "C"), tile.New(12, "A"), tile.New(1, "B")}
+	bd := board.New()
+	optional := Optional{Board: bd, Tileset: tileset.NewWithTiles(openingTiles, nil), Seed: 3}
+
+	game, _ := New(players, optional)
+
+	first := game.OpeningTiles()[game.InitialPlayerNumber()]
+	if tile.Coords(first) != "12A" {
+		t.Errorf("Player who drew the tile closest to 1A must go first, got the one who drew %s", tile.Coords(first))
+	}
+	if game.CurrentPlayerNumber() != game.InitialPlayerNumber() {
+		t.Errorf("Player who drew the tile closest to 1A must be in turn, got player %d", game.CurrentPlayerNumber())
+	}
+	for _, tl := range openingTiles {
+		if bd.Cell(tl.Number(), tl.Letter()).Type() != interfaces.UnincorporatedOwner {
+			t.Errorf("Opening tile %s must be placed on board", tile.Coords(tl))
+		}
+	}
+}
+
+func TestNewGameWithSameSeedIsDeterministic(t *testing.T) {
+	players1, _ := setup()
+	players2, _ := setup()
+	game1, _ := New(players1, Optional{Seed: 42})
+	game2, _ := New(players2, Optional{Seed: 42})
+
+	if game1.Seed() != 42 {
+		t.Errorf("Game must store the seed it was created with, expected %d, got %d", 42, game1.Seed())
+	}
+	if game1.CurrentPlayerNumber() != game2.CurrentPlayerNumber() {
+		t.Errorf("Games with the same seed must have the same starting player, got %d and %d", game1.CurrentPlayerNumber(), game2.CurrentPlayerNumber())
+	}
+	for i := range players1 {
+		for j, tl := range players1[i].Tiles() {
+			other := players2[i].Tiles()[j]
+			if tl.Number() != other.Number() || tl.Letter() != other.Letter() {
+				t.Errorf("Games with the same seed must deal the same hands, player %d got %d%s and %d%s", i, tl.Number(), tl.Letter(), other.Number(), other.Letter())
+			}
+		}
+	}
+}
+
 func TestAreEndConditionsReached(t *testing.T) {
 	players, optional := setup()
 	game, _ := New(players, optional)
@@ -322,10 +369,10 @@ func TestPlayTileMergeCorporationsAndGrow(t *testing.T) {
 	sell := map[interfaces.Corporation]int{optional.Corporations[0]: 6}
 	trade := map[interfaces.Corporation]int{}
 	game.stateMachine.(*mocks.StateMachine).FakeStateName = interfaces.SellTradeStateName
-	game.mergeCorps = map[string][]interfaces.Corporation{
+	game.merge = newMerge(map[string][]interfaces.Corporation{
 		"acquirer": []interfaces.Corporation{optional.Corporations[1]},
 		"defunct":  []interfaces.Corporation{optional.Corporations[0]},
-	}
+	}, 0)
 	game.lastPlayedTile = tileToPlay
 	game.SellTrade(sell, trade)
 
@@ -416,6 +463,23 @@ func TestBuyStockWithNotEnoughCash(t *testing.T) {
 	}
 }
 
+func TestBuyStockNegativeAmount(t *testing.T) {
+	players, optional := setup()
+	optional.Corporations[0].Grow(2)
+	optional.Corporations[1].Grow(2)
+	optional.StateMachine = &mocks.StateMachine{FakeStateName: interfaces.BuyStockStateName, TimesCalled: map[string]int{}}
+	game, _ := New(players, optional)
+	game.currentPlayerNumber = 0
+
+	buys := map[interfaces.Corporation]int{optional.Corporations[0]: 6, optional.Corporations[1]: -3}
+	if err := game.BuyStock(buys); err == nil || err.Error() != InvalidSharesAmount {
+		t.Errorf("Buying a negative amount of stock shares must fail with error %s, got %v", InvalidSharesAmount, err)
+	}
+	if players[0].Cash() != 6000 || players[0].Shares(optional.Corporations[0]) != 0 {
+		t.Errorf("Player assets must not change after a rejected buy, got %d$ and %d shares", players[0].Cash(), players[0].Shares(optional.Corporations[0]))
+	}
+}
+
 func TestBuyStockAndEndGame(t *testing.T) {
 	players, optional := setup()
 	optional.Corporations[0].Grow(42)
@@ -484,30 +548,48 @@ func TestIsGrowTilePlayable(t *testing.T) {
 	}
 }
 
+// Corporations 0, 1 and 2 have the same size, so after choosing 1 as the acquirer,
+// the mergemaker must also choose which one of 0 and 2 is dealt with first
 func TestUntieMerge(t *testing.T) {
 	players, optional := setup()
 	optional.StateMachine = &mocks.StateMachine{FakeStateName: interfaces.UntieMergeStateName, TimesCalled: map[string]int{}}
+	optional.Corporations[0].Grow(5)
+	optional.Corporations[1].Grow(5)
+	optional.Corporations[2].Grow(5)
+	optional.Corporations[3].Grow(2)
 
 	game, _ := New(players, optional)
-	game.mergeCorps = map[string][]interfaces.Corporation{
+	game.merge = newMerge(map[string][]interfaces.Corporation{
 		"acquirer": []interfaces.Corporation{optional.Corporations[0], optional.Corporations[1], optional.Corporations[2]},
 		"defunct":  []interfaces.Corporation{optional.Corporations[3]},
-	}
+	}, 0)
 	game.lastPlayedTile = &mocks.Tile{FakeNumber: 5, FakeLetter: "E"}
 	players[0].(*mocks.Player).FakeShares[optional.Corporations[0]] = 6
 	players[0].(*mocks.Player).FakeShares[optional.Corporations[2]] = 6
 	players[0].(*mocks.Player).FakeShares[optional.Corporations[3]] = 6
 
 	game.UntieMerge(optional.Corporations[1])
-	if game.mergeCorps["acquirer"][0] != optional.Corporations[1] {
+	if game.merge.acquirer() != optional.Corporations[1] {
 		t.Errorf("Tied merge not untied")
 	}
-	if len(game.mergeCorps["defunct"]) != 3 {
-		t.Errorf("Wrong number of defunct corporations after merge untie, expected %d, got %d", 3, len(game.mergeCorps["defunct"]))
+	if len(game.merge.corps["defunct"]) != 3 {
+		t.Errorf("Wrong number of defunct corporations after merge untie, expected %d, got %d", 3, len(game.merge.corps["defunct"]))
+	}
+	if game.stateMachine.(*mocks.StateMachine).TimesCalled["ToUntieMerge"] != 1 {
+		t.Errorf("Game must stay in UntieMerge state as there are tied defunct corporations")
+	}
+	tied := game.TiedCorps()
+	if len(tied) != 2 || tied[0] != optional.Corporations[0] || tied[1] != optional.Corporations[2] {
+		t.Errorf("Corporations 0 and 2 must be tied as defunct corporations")
 	}
+
+	game.UntieMerge(optional.Corporations[2])
 	if game.stateMachine.(*mocks.StateMachine).TimesCalled["ToSellTrade"] != 1 {
 		t.Errorf("Game must change its state to SellTrade")
 	}
+	if game.merge.currentDefunct() != optional.Corporations[2] {
+		t.Errorf("Corporation 2 must be the first defunct corporation to be dealt with")
+	}
 }
 
 func TestDeactivatePlayer(t *testing.T) {
@@ -527,6 +609,21 @@ func TestDeactivatePlayer(t *testing.T) {
 	}
 }
 
+func TestDeactivateInactivePlayer(t *testing.T) {
+	players, optional := setup()
+	optional.StateMachine = &mocks.StateMachine{FakeStateName: interfaces.UntieMergeStateName, TimesCalled: map[string]int{}}
+
+	game, _ := New(players, optional)
+	game.DeactivatePlayer(players[1])
+	game.DeactivatePlayer(players[1])
+	if game.stateMachine.(*mocks.StateMachine).TimesCalled["ToInsufficientPlayers"] != 1 {
+		t.Errorf("Deactivating an inactive player must not change the game state")
+	}
+	if len(game.actions) != 1 {
+		t.Errorf("Deactivating an inactive player must not be recorded, expected %d actions, got %d", 1, len(game.actions))
+	}
+}
+
 func setup() ([]interfaces.Player, Optional) {
 	players := []interfaces.Player{
 		&mocks.Player{FakeShares: map[interfaces.Corporation]int{}, FakeCash: 6000, TimesCalled: map[string]int{}, FakeActive: true},
@@ -576,3 +673,131 @@ func slicesSameContent(slice1 []interfaces.Player, slice2 []interfaces.Player) b
 	}
 	return true
 }
+
+// All tiles are placed far from each other, so no corporation is ever founded. Once
+// all of them are on board, nobody can place a tile and the game must end.
+func TestGameEndsWhenNoTilesCanBePlaced(t *testing.T) {
+	tiles := []interfaces.Tile{}
+	for _, letter := range []string{"A", "C", "E", "G"} {
+		for number := 1; number < 12; number += 2 {
+			tiles = append(tiles, tile.New(number, letter))
+		}
+	}
+	players := newDefaultPlayers(3)
+	game, _ := New(players, Optional{Tileset: tileset.NewWithTiles(tiles[:21], nil), Seed: 2})
+
+	for i := 0; i < 18; i++ {
+		game.PlayTile(game.CurrentPlayer().Tiles()[0])
+	}
+	if game.GameStateName() != interfaces.EndGameStateName {
+		t.Errorf("Game must end when no tiles can be placed, got state %s", game.GameStateName())
+	}
+	if _, err := game.Results(); err != nil {
+		t.Errorf("Results must be available after a stalled game ends, got error %s", err)
+	}
+}
+
+// Sets up a game without dealing tiles in which all seven corporations are on board,
+// along with isolated unincorporated tiles at C2, C5, C8, C11, G2 and G5, so the
+// tiles returned by unplayableHand would found an eighth corporation.
+// Players' hands are the passed tiles, and the tileset holds the passed remaining ones.
+func setupFullBoard(hands [][]interfaces.Tile, remaining []interfaces.Tile) *Game {
+	bd := board.New()
+	game, _ := newGame(newDefaultPlayers(len(hands)), Optional{Board: bd, Tileset: tileset.NewWithTiles(remaining, nil), Seed: 1})
+	for i, coords := range []string{"1A", "4A", "7A", "10A", "1I", "4I", "7I"} {
+		tl, _ := tile.Parse(coords)
+		bd.SetOwner(game.corporations[i], []interfaces.Tile{tl, tile.New(tl.Number()+1, tl.Letter())})
+		game.corporations[i].Grow(2)
+	}
+	for _, coords := range []string{"2C", "5C", "8C", "11C", "2G", "5G"} {
+		tl, _ := tile.Parse(coords)
+		bd.PutTile(tl)
+	}
+	for n, hand := range hands {
+		for _, tl := range hand {
+			game.Player(n).PickTile(tl)
+		}
+	}
+	return game
+}
+
+// Returns six tiles which cannot be placed in the board built by setupFullBoard
+func unplayableHand() []interfaces.Tile {
+	hand := []interfaces.Tile{}
+	for _, coords := range []string{"3C", "6C", "9C", "12C", "3G", "6G"} {
+		tl, _ := tile.Parse(coords)
+		hand = append(hand, tl)
+	}
+	return hand
+}
+
+// Returns six tiles which can be placed in the board built by setupFullBoard
+func playableHand() []interfaces.Tile {
+	hand := []interfaces.Tile{}
+	for _, coords := range []string{"12E", "12F", "12G", "12H", "10E", "10F"} {
+		tl, _ := tile.Parse(coords)
+		hand = append(hand, tl)
+	}
+	return hand
+}
+
+func TestPlayerWithoutPlayableTilesOnlyBuysStock(t *testing.T) {
+	game := setupFullBoard([][]interfaces.Tile{playableHand(), unplayableHand(), playableHand()}, []interfaces.Tile{tile.New(1, "E")})
+	game.stateMachine.ToBuyStock()
+
+	if err := game.BuyStock(map[interfaces.Corporation]int{}); err != nil {
+		t.Fatalf("Buying no stock must be allowed, got error %s", err)
+	}
+	if game.CurrentPlayerNumber() != 1 || game.GameStateName() != interfaces.BuyStockStateName {
+		t.Errorf("Player 1 must skip placing a tile and go on buying stock, got player %d in state %s", game.CurrentPlayerNumber(), game.GameStateName())
+	}
+}
+
+func TestStalledGameEnds(t *testing.T) {
+	game := setupFullBoard([][]interfaces.Tile{unplayableHand(), unplayableHand(), unplayableHand()}, []interfaces.Tile{})
+	game.stateMachine.ToBuyStock()
+
+	if err := game.BuyStock(map[interfaces.Corporation]int{}); err != nil {
+		t.Fatalf("Buying no stock must be allowed, got error %s", err)
+	}
+	if game.GameStateName() != interfaces.EndGameStateName {
+		t.Errorf("Game must end when no tiles are left and nobody can place one, got state %s", game.GameStateName())
+	}
+}
+
+func TestIsStalled(t *testing.T) {
+	game := setupFullBoard([][]interfaces.Tile{unplayableHand(), unplayableHand(), unplayableHand()}, []interfaces.Tile{})
+	if !game.isStalled() {
+		t.Errorf("Game must be stalled when no tiles are left and nobody can place one")
+	}
+
+	game = setupFullBoard([][]interfaces.Tile{unplayableHand(), unplayableHand(), unplayableHand()}, []interfaces.Tile{tile.New(1, "E")})
+	if game.isStalled() {
+		t.Errorf("Game must not be stalled while there are tiles left to draw")
+	}
+
+	game = setupFullBoard([][]interfaces.Tile{unplayableHand(), unplayableHand(), playableHand()}, []interfaces.Tile{})
+	if game.isStalled() {
+		t.Errorf("Game must not be stalled while an active player can place a tile")
+	}
+	game.Player(2).Deactivate()
+	if !game.isStalled() {
+		t.Errorf("Tiles of deactivated players must not prevent the game from being stalled")
+	}
+}
+
+func TestDrawTileOnlyBelowSixTiles(t *testing.T) {
+	game := setupFullBoard([][]interfaces.Tile{playableHand(), playableHand(), playableHand()}, []interfaces.Tile{tile.New(1, "E"), tile.New(1, "F")})
+	if err := game.drawTile(); err != nil {
+		t.Fatalf("Drawing a tile must not fail, got error %s", err)
+	}
+	if len(game.CurrentPlayer().Tiles()) != 6 || len(game.tileset.Tiles()) != 2 {
+		t.Errorf("Player with 6 tiles must not draw, got %d tiles in hand and %d left", len(game.CurrentPlayer().Tiles()), len(game.tileset.Tiles()))
+	}
+
+	game.CurrentPlayer().DiscardTile(game.CurrentPlayer().Tiles()[0])
+	game.drawTile()
+	if len(game.CurrentPlayer().Tiles()) != 6 || len(game.tileset.Tiles()) != 1 {
+		t.Errorf("Player with 5 tiles must draw one, got %d tiles in hand and %d left", len(game.CurrentPlayer().Tiles()), len(game.tileset.Tiles()))
+	}
+}
@@ -4,4 +4,7 @@ package interfaces
 type Tileset interface {
 	Draw() (Tile, error)
 	Add(tiles []Tile) Tileset
+	// Tiles returns the tiles left to draw, in drawing order. It is needed to take
+	// game snapshots, so tilesets implemented outside this module must provide it.
+	Tiles() []Tile
 }
@@ -0,0 +1,72 @@
+package acquire
+
+import "github.com/svera/acquire/interfaces"
+
+// merge stores the context of a merge in progress.
+// Game rules state that defunct stock is sold, and bonuses paid, at the values
+// the corporations had before the merger, so prices of all corporations involved
+// are frozen when the merging tile is placed.
+type merge struct {
+	// corps holds the merging corporations under the "acquirer" and "defunct" keys,
+	// as returned by Board.TileMergeCorporations
+	corps  map[string][]interfaces.Corporation
+	prices map[interfaces.Corporation]interfaces.Prices
+	// mergemaker is the player who placed the merging tile. The turn is passed
+	// to defunct corporations stockholders and returned to the mergemaker afterwards
+	mergemaker int
+	// Position in corps["defunct"] of the defunct corporation being dealt with
+	defunctIndex int
+	// Stockholders of the current defunct corporation who still have to sell, trade or hold
+	sellTradePlayers []int
+}
+
+// Returns a merge context for the passed corporations with their current prices frozen
+func newMerge(corps map[string][]interfaces.Corporation, mergemaker int) *merge {
+	m := &merge{
+		corps:      corps,
+		prices:     map[interfaces.Corporation]interfaces.Prices{},
+		mergemaker: mergemaker,
+	}
+	for _, role := range corps {
+		for _, corp := range role {
+			m.prices[corp] = currentPrices(corp)
+		}
+	}
+	return m
+}
+
+// Returns the prices the passed corporation had when the merge started,
+// or its current ones if it is not involved in the merge
+func (m *merge) pricesOf(corp interfaces.Corporation) interfaces.Prices {
+	if prices, ok := m.prices[corp]; ok {
+		return prices
+	}
+	return currentPrices(corp)
+}
+
+// Returns the defunct corporation being dealt with, or nil if all of them are done
+func (m *merge) currentDefunct() interfaces.Corporation {
+	if m.defunctIndex >= len(m.corps["defunct"]) {
+		return nil
+	}
+	return m.corps["defunct"][m.defunctIndex]
+}
+
+// Returns the corporation surviving the merge, or nil if it is still tied
+func (m *merge) acquirer() interfaces.Corporation {
+	if len(m.corps["acquirer"]) != 1 {
+		return nil
+	}
+	return m.corps["acquirer"][0]
+}
+
+// Removes the passed player from the stockholders who still have to sell, trade or hold
+func (m *merge) removeSellTradePlayer(number int) {
+	pending := []int{}
+	for _, n := range m.sellTradePlayers {
+		if n != number {
+			pending = append(pending, n)
+		}
+	}
+	m.sellTradePlayers = pending
+}
@@ -7,6 +7,7 @@ import (
 // Tileset is a structure that implements the Tileset interface for testing
 type Tileset struct {
 	FakeTile    interfaces.Tile
+	FakeTiles   []interfaces.Tile
 	FakeError   error
 	TimesCalled map[string]int
 }
@@ -21,3 +22,8 @@ func (t *Tileset) Add([]interfaces.Tile) interfaces.Tileset {
 	t.TimesCalled["Add"]++
 	return t
 }
+
+// Tiles mocks the Tiles method defined in the Tileset interface
+func (t *Tileset) Tiles() []interfaces.Tile {
+	return t.FakeTiles
+}
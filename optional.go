@@ -9,4 +9,15 @@ type Optional struct {
 	Corporations [7]interfaces.Corporation
 	Tileset      interfaces.Tileset
 	StateMachine interfaces.StateMachine
+	// Seed initialises the game's random generator, which decides tile order
+	// and starting player. If zero, a seed based on the current time is used.
+	Seed int64
+	// BonusRounding is applied to every bonus amount paid to a stockholder.
+	// If nil, RoundUpToHundred is used.
+	BonusRounding RoundingPolicy
+	// TimeControl sets optional time limits for players' moves. By default there are none.
+	TimeControl TimeControl
+	// HiddenAssets plays the variant in which players' cash and stock shares are kept secret,
+	// so views of the game given to players (see Game.ViewFor) do not show rivals' ones.
+	HiddenAssets bool
 }
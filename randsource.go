@@ -0,0 +1,36 @@
+package acquire
+
+import "math/rand"
+
+// Random source which counts how many values it has generated, so the position of a
+// generator can be stored in snapshots and reached again when restoring them
+type countingSource struct {
+	src   rand.Source64
+	calls uint64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.calls++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.calls++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.calls = 0
+}
+
+// Advances the source until it has generated the passed number of values
+func (s *countingSource) skipTo(calls uint64) {
+	for s.calls < calls {
+		s.Int63()
+	}
+}
@@ -0,0 +1,100 @@
+package acquire
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/svera/acquire/interfaces"
+)
+
+// Standing holds the final result of a player in a finished game
+type Standing struct {
+	Player int `json:"player"`
+	// Rank is the final position of the player. Players with the same final cash share
+	// the same rank, and the next rank is skipped accordingly (for example 1, 1, 3)
+	Rank int `json:"rank"`
+	Cash int `json:"cash"`
+	// Bonuses is the total amount of majority and minority bonuses received at game end
+	Bonuses int `json:"bonuses"`
+	// Liquidations holds the stock shares sold back to the bank at game end
+	Liquidations []Liquidation `json:"liquidations"`
+}
+
+// Liquidation holds the stock shares of a corporation sold by a player at game end
+type Liquidation struct {
+	Corporation int `json:"corporation"`
+	Shares      int `json:"shares"`
+	Price       int `json:"price"`
+}
+
+// Amount returns the cash received for the liquidated stock shares
+func (l Liquidation) Amount() int {
+	return l.Shares * l.Price
+}
+
+// Results returns the final standings of all active players, ordered by rank and,
+// within the same rank, by player number. It can only be called once the game has ended.
+func (g *Game) Results() ([]Standing, error) {
+	if g.stateMachine.CurrentStateName() != interfaces.EndGameStateName {
+		return nil, errors.New(ActionNotAllowed)
+	}
+	return append([]Standing{}, g.results...), nil
+}
+
+// Winners returns the numbers of the players with the most money at game end.
+// More than one player is returned in case of a tie.
+func (g *Game) Winners() ([]int, error) {
+	standings, err := g.Results()
+	if err != nil {
+		return nil, err
+	}
+	winners := []int{}
+	for _, standing := range standings {
+		if standing.Rank == 1 {
+			winners = append(winners, standing.Player)
+		}
+	}
+	return winners, nil
+}
+
+// Builds the final standings of active players with the passed end game breakdown,
+// indexed by player number
+func (g *Game) rankResults(bonuses []int, liquidations [][]Liquidation) []Standing {
+	standings := []Standing{}
+	for number, pl := range g.players {
+		if !pl.Active() {
+			continue
+		}
+		standings = append(standings, Standing{
+			Player:       number,
+			Cash:         pl.Cash(),
+			Bonuses:      bonuses[number],
+			Liquidations: append([]Liquidation{}, liquidations[number]...),
+		})
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Cash > standings[j].Cash
+	})
+	cash := make([]int, len(standings))
+	for i := range standings {
+		cash[i] = standings[i].Cash
+	}
+	for i, rank := range competitionRanks(cash) {
+		standings[i].Rank = rank
+	}
+	return standings
+}
+
+// Returns the rank of each of the passed values, which must be sorted descendently.
+// Equal values share the same rank, and the next rank is skipped accordingly.
+func competitionRanks(values []int) []int {
+	ranks := make([]int, len(values))
+	for i := range values {
+		if i > 0 && values[i] == values[i-1] {
+			ranks[i] = ranks[i-1]
+		} else {
+			ranks[i] = i + 1
+		}
+	}
+	return ranks
+}
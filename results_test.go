@@ -0,0 +1,57 @@
+package acquire
+
+import (
+	"testing"
+
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/mocks"
+)
+
+func TestResultsNotAvailableBeforeEndGame(t *testing.T) {
+	players, optional := setup()
+	game, _ := New(players, optional)
+
+	if _, err := game.Results(); err == nil || err.Error() != ActionNotAllowed {
+		t.Errorf("Results must not be available before game ends")
+	}
+}
+
+func TestResultsWithTiedWinners(t *testing.T) {
+	players, optional := setup()
+	optional.Corporations[0].Grow(42)
+	optional.Corporations[0].(*mocks.Corporation).FakeIsActive = true
+	optional.Corporations[0].(*mocks.Corporation).FakeIsSafe = true
+	optional.Corporations[0].(*mocks.Corporation).FakeMajorityBonus = 10000
+	optional.Corporations[0].(*mocks.Corporation).FakeMinorityBonus = 5000
+	optional.Corporations[0].(*mocks.Corporation).FakeStockPrice = 1000
+	players[0].AddShares(optional.Corporations[0], 2)
+	players[1].AddShares(optional.Corporations[0], 2)
+	optional.StateMachine = &mocks.StateMachine{FakeStateName: interfaces.EndGameStateName, TimesCalled: map[string]int{}}
+	game, _ := New(players, optional)
+	game.finish()
+
+	standings, err := game.Results()
+	if err != nil {
+		t.Fatalf("Results must be available after game ends, got error %s", err)
+	}
+	// 6000$ (base cash) + 7500$ (half of majority and minority bonuses) + 2000$ (2 shares sold at 1000$)
+	expected := []Standing{
+		{Player: 0, Rank: 1, Cash: 15500, Bonuses: 7500, Liquidations: []Liquidation{{Corporation: 0, Shares: 2, Price: 1000}}},
+		{Player: 1, Rank: 1, Cash: 15500, Bonuses: 7500, Liquidations: []Liquidation{{Corporation: 0, Shares: 2, Price: 1000}}},
+		{Player: 2, Rank: 3, Cash: 6000, Bonuses: 0, Liquidations: []Liquidation{}},
+	}
+	for i := range expected {
+		if standings[i].Player != expected[i].Player || standings[i].Rank != expected[i].Rank ||
+			standings[i].Cash != expected[i].Cash || standings[i].Bonuses != expected[i].Bonuses ||
+			len(standings[i].Liquidations) != len(expected[i].Liquidations) {
+			t.Errorf("Expected standing %v, got %v", expected[i], standings[i])
+		}
+	}
+	if standings[0].Liquidations[0].Amount() != 2000 {
+		t.Errorf("Expected liquidation amount of %d$, got %d$", 2000, standings[0].Liquidations[0].Amount())
+	}
+	winners, _ := game.Winners()
+	if len(winners) != 2 || winners[0] != 0 || winners[1] != 1 {
+		t.Errorf("Expected players 0 and 1 as winners, got %v", winners)
+	}
+}
@@ -0,0 +1,26 @@
+package acquire
+
+// RoundingPolicy is a function which rounds every majority or minority bonus amount
+// paid to a stockholder. Only split bonuses are affected in practice, as whole
+// bonuses are always multiples of $100.
+type RoundingPolicy func(amount int) int
+
+// RoundUpToHundred rounds the passed amount up to the nearest $100, as stated
+// by the official rules. This is the default rounding policy.
+func RoundUpToHundred(amount int) int {
+	if remainder := amount % 100; remainder != 0 {
+		return amount + 100 - remainder
+	}
+	return amount
+}
+
+// RoundDownToHundred rounds the passed amount down to the nearest $100
+func RoundDownToHundred(amount int) int {
+	return amount - amount%100
+}
+
+// NoRounding returns the passed amount unchanged, so split bonuses are
+// calculated with plain integer division
+func NoRounding(amount int) int {
+	return amount
+}
@@ -0,0 +1,59 @@
+package acquire
+
+import (
+	"testing"
+
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/mocks"
+)
+
+func TestRoundingPolicies(t *testing.T) {
+	if RoundUpToHundred(333) != 400 || RoundUpToHundred(700) != 700 {
+		t.Errorf("RoundUpToHundred must round amounts up to the nearest 100")
+	}
+	if RoundDownToHundred(750) != 700 || RoundDownToHundred(700) != 700 {
+		t.Errorf("RoundDownToHundred must round amounts down to the nearest 100")
+	}
+	if NoRounding(333) != 333 {
+		t.Errorf("NoRounding must not modify amounts")
+	}
+}
+
+func TestBonusDistribution(t *testing.T) {
+	var tests = []struct {
+		name     string
+		shares   []int
+		rounding RoundingPolicy
+		expected []int
+	}{
+		{"single holder gets both bonuses", []int{5, 0, 0, 0}, nil, []int{3000, 0, 0, 0}},
+		{"majority and minority", []int{5, 3, 0, 0}, nil, []int{2000, 1000, 0, 0}},
+		{"two-way majority tie", []int{5, 5, 2, 0}, nil, []int{1500, 1500, 0, 0}},
+		{"three-way majority tie", []int{4, 4, 4, 1}, nil, []int{1000, 1000, 1000, 0}},
+		{"four-way majority tie rounds up", []int{2, 2, 2, 2}, nil, []int{800, 800, 800, 800}},
+		{"three-way minority tie rounds up", []int{6, 3, 3, 3}, nil, []int{2000, 400, 400, 400}},
+		{"three-way minority tie without rounding", []int{6, 3, 3, 3}, NoRounding, []int{2000, 333, 333, 333}},
+		{"four-way majority tie rounding down", []int{2, 2, 2, 2}, RoundDownToHundred, []int{700, 700, 700, 700}},
+	}
+
+	for _, tt := range tests {
+		players, optional := setup()
+		players = append(players, &mocks.Player{FakeShares: map[interfaces.Corporation]int{}, FakeCash: 6000, TimesCalled: map[string]int{}, FakeActive: true})
+		optional.BonusRounding = tt.rounding
+		corp := optional.Corporations[0].(*mocks.Corporation)
+		corp.FakeMajorityBonus = 2000
+		corp.FakeMinorityBonus = 1000
+		for i, amount := range tt.shares {
+			players[i].AddShares(corp, amount)
+		}
+		game, _ := New(players, optional)
+
+		amounts := game.bonuses(corp, currentPrices(corp))
+		for i := range tt.expected {
+			if amounts[i] != tt.expected[i] {
+				t.Errorf("%s: expected bonuses %v, got %v", tt.name, tt.expected, amounts)
+				break
+			}
+		}
+	}
+}
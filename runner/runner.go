@@ -0,0 +1,158 @@
+// Package runner plays complete Acquire games between bots, without any human intervention
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/bots"
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/player"
+)
+
+const (
+	// MaxActionsReached is an error message returned when a game does not end
+	// within the configured maximum number of actions
+	MaxActionsReached = "max_actions_reached"
+	// GameNotFinished is an error message returned when a game stops before reaching its end,
+	// for example because too many bots were deactivated
+	GameNotFinished = "game_not_finished"
+
+	// DefaultMaxActions is the maximum number of actions used when none is configured
+	DefaultMaxActions = 5000
+)
+
+// Config holds the settings of a game to be run
+type Config struct {
+	// Bots holds the name of the bot playing each seat, as accepted by bots.Create
+	Bots []string
+	// BotConfigs holds the config passed to the bot of each seat. Seats without one
+	// get a default config. Bots without a seed get one derived from the game seed and
+	// their seat, so running again with the same seed plays the same game.
+	BotConfigs []bots.Config
+	// Seed initialises the game's random generator. If zero, a seed based
+	// on the current time is used.
+	Seed int64
+	// MaxActions is the maximum number of bot messages to process before giving up.
+	// If zero, DefaultMaxActions is used.
+	MaxActions int
+	// HiddenAssets plays the variant in which bots are not told rivals' cash and stock shares
+	HiddenAssets bool
+}
+
+// Fault stores a bot message which could not be applied to the game.
+// The bot which sent it is deactivated.
+type Fault struct {
+	Player  int
+	Message bots.Message
+	Err     string
+}
+
+// Result stores the outcome of a game run
+type Result struct {
+	Seed      int64
+	Bots      []string
+	Actions   int
+	Standings []acquire.Standing
+	Winners   []int
+	Faults    []Fault
+	Log       acquire.Log
+}
+
+// Run plays a full game between the configured bots and returns its result.
+// If the game does not end, the result is returned along with an error.
+func Run(cfg Config) (Result, error) {
+	if cfg.MaxActions == 0 {
+		cfg.MaxActions = DefaultMaxActions
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	players := []interfaces.Player{}
+	seats := []interfaces.Bot{}
+	defer func() { closeBots(seats) }()
+	for i, name := range cfg.Bots {
+		var botCfg bots.Config
+		if i < len(cfg.BotConfigs) {
+			botCfg = cfg.BotConfigs[i]
+		}
+		if botCfg.Seed == 0 {
+			botCfg.Seed = bots.SeatSeed(cfg.Seed, i)
+		}
+		bot, err := bots.Create(name, botCfg)
+		if err != nil {
+			return Result{}, err
+		}
+		seats = append(seats, bot)
+		players = append(players, player.New())
+	}
+	game, err := acquire.New(players, acquire.Optional{Seed: cfg.Seed, HiddenAssets: cfg.HiddenAssets})
+	if err != nil {
+		return Result{}, err
+	}
+	res := Result{Seed: game.Seed(), Bots: cfg.Bots}
+
+	for ; res.Actions < cfg.MaxActions && isRunning(game); res.Actions++ {
+		number := game.CurrentPlayerNumber()
+		msg, err := play(game, seats[number], number)
+		if err == nil {
+			err = bots.Apply(game, number, msg)
+		}
+		if err != nil {
+			res.Faults = append(res.Faults, Fault{Player: number, Message: msg, Err: err.Error()})
+			game.DeactivatePlayer(players[number])
+		}
+	}
+	res.Log = game.Log()
+
+	if game.GameStateName() != interfaces.EndGameStateName {
+		if res.Actions == cfg.MaxActions {
+			return res, errors.New(MaxActionsReached)
+		}
+		return res, errors.New(GameNotFinished)
+	}
+	res.Standings, _ = game.Results()
+	res.Winners, _ = game.Winners()
+	return res, nil
+}
+
+// Updates the bot with the game status as seen by the player it plays for
+// and returns its next move. Bots panicking are handled as if they returned an error.
+func play(game *acquire.Game, bot interfaces.Bot, number int) (msg bots.Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("bot panicked: %v", r)
+		}
+	}()
+	st, err := bots.StatusFor(game, number)
+	if err != nil {
+		return msg, err
+	}
+	bot.Update(st)
+	msg, ok := bot.Play().(bots.Message)
+	// Bots which can fail, such as out-of-process ones, report why through an Err method
+	if failing, isFailing := bot.(interface{ Err() error }); isFailing && failing.Err() != nil {
+		return msg, failing.Err()
+	}
+	if !ok {
+		return msg, errors.New(bots.UnknownMessageType)
+	}
+	return msg, nil
+}
+
+func isRunning(game *acquire.Game) bool {
+	state := game.GameStateName()
+	return state != interfaces.EndGameStateName && state != interfaces.InsufficientPlayersStateName
+}
+
+// Releases the resources held by bots which need it, such as out-of-process ones
+func closeBots(seats []interfaces.Bot) {
+	for _, bot := range seats {
+		if closer, ok := bot.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
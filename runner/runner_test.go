@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/bots"
+)
+
+func TestRunRandomBots(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		res, err := Run(Config{Bots: []string{"random", "random", "random", "random"}, Seed: seed})
+		if err != nil {
+			t.Fatalf("Game with seed %d must end, got error %s after %d actions", seed, err, res.Actions)
+		}
+		if len(res.Standings) != 4 || len(res.Winners) == 0 {
+			t.Errorf("Game with seed %d must have standings for all players and at least a winner", seed)
+		}
+		if _, err := acquire.Replay(res.Log); err != nil {
+			t.Errorf("Game with seed %d must be replayable from its log, got error %s", seed, err)
+		}
+	}
+}
+
+func TestRunIsReproducible(t *testing.T) {
+	cfg := Config{Bots: []string{"random", "greedy", "random"}, BotConfigs: []bots.Config{{}, {Difficulty: bots.Easy}}, Seed: 3}
+	first, _ := Run(cfg)
+	second, _ := Run(cfg)
+	if !reflect.DeepEqual(first.Log, second.Log) {
+		t.Errorf("Games run with the same seed must be the same")
+	}
+}
+
+func TestRunMaxActionsReached(t *testing.T) {
+	res, err := Run(Config{Bots: []string{"random", "random", "random"}, Seed: 1, MaxActions: 10})
+	if err == nil || err.Error() != MaxActionsReached {
+		t.Errorf("Run must return error %s when the game does not end in time", MaxActionsReached)
+	}
+	if res.Actions != 10 {
+		t.Errorf("Run must stop after %d actions, got %d", 10, res.Actions)
+	}
+}
+
+func TestRunUnknownBot(t *testing.T) {
+	if _, err := Run(Config{Bots: []string{"random", "random", "clairvoyant"}}); err == nil || err.Error() != bots.BotNotFound {
+		t.Errorf("Run must return error %s when a bot does not exist", bots.BotNotFound)
+	}
+}
+
+func TestRunUnknownDifficulty(t *testing.T) {
+	cfg := Config{Bots: []string{"random", "greedy", "greedy"}, BotConfigs: []bots.Config{{}, {Difficulty: "nightmare"}}}
+	if _, err := Run(cfg); err == nil || err.Error() != bots.UnknownDifficulty {
+		t.Errorf("Run must return error %s when a bot difficulty does not exist", bots.UnknownDifficulty)
+	}
+}
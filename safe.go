@@ -0,0 +1,58 @@
+package acquire
+
+import "sync"
+
+// SafeGame wraps a game so it can be used from several goroutines, for example to let
+// spectators look at it while a player acts. Changes are serialized, while reads can be done
+// at the same time between them, always seeing the game between two changes.
+// Once wrapped, the game must only be accessed through the wrapper.
+type SafeGame struct {
+	mu   sync.RWMutex
+	game *Game
+}
+
+// NewSafe wraps the passed game
+func NewSafe(game *Game) *SafeGame {
+	return &SafeGame{game: game}
+}
+
+// Apply executes the passed action in the game, see Game.Apply
+func (s *SafeGame) Apply(action Action) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.game.Apply(action)
+}
+
+// CheckTimeout applies the time control fallback if the current player has run out of time,
+// see Game.CheckTimeout
+func (s *SafeGame) CheckTimeout() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.game.CheckTimeout()
+}
+
+// Snapshot returns a copy of the state of the game, see Game.Snapshot
+func (s *SafeGame) Snapshot() Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.game.Snapshot()
+}
+
+// Read calls the passed function with the game, which must only be queried and not
+// changed. References to game elements, such as players or corporations, must not be kept
+// after the function returns, as they are not safe to use outside it. The function must not
+// call methods of the wrapper.
+func (s *SafeGame) Read(read func(game *Game)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	read(s.game)
+}
+
+// Update calls the passed function with the game, which can change it
+// without any other goroutine accessing it meanwhile. It returns the function error.
+// As for Read, the function must not call methods of the wrapper.
+func (s *SafeGame) Update(update func(game *Game) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return update(s.game)
+}
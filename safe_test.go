@@ -0,0 +1,67 @@
+package acquire
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/svera/acquire/interfaces"
+)
+
+// Meant to be run with the race detector: several goroutines look at the game
+// while another one plays it
+func TestSafeGameConcurrentAccess(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	game, _ := New(newDefaultPlayers(4), Optional{Seed: 1, TimeControl: TimeControl{PerMove: time.Second, Clock: clock}})
+	safe := NewSafe(game)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				snapshot := safe.Snapshot()
+				if len(snapshot.Players) != 4 {
+					t.Errorf("Snapshot must hold all players, got %d", len(snapshot.Players))
+				}
+				safe.Read(func(g *Game) {
+					for _, tl := range g.CurrentPlayer().Tiles() {
+						g.IsTilePlayable(tl)
+					}
+					g.Valuations()
+					g.TiedCorps()
+					g.Board().Cell(1, "A")
+				})
+			}
+		}()
+	}
+
+	// Every move times out and is played automatically
+	for i := 0; i < 2000; i++ {
+		ended := false
+		safe.Update(func(g *Game) error {
+			clock.advance(2 * time.Second)
+			g.CheckTimeout()
+			ended = !g.isRunning()
+			return nil
+		})
+		if ended {
+			break
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	safe.Read(func(g *Game) {
+		if g.GameStateName() != interfaces.EndGameStateName {
+			t.Errorf("Game played through the wrapper must end, got state %s", g.GameStateName())
+		}
+	})
+}
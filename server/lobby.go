@@ -0,0 +1,428 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/bots"
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/player"
+)
+
+// Lobby states
+const (
+	WaitingState  = "waiting"
+	PlayingState  = "playing"
+	FinishedState = "finished"
+)
+
+// Update types pushed to players
+const (
+	LobbyUpdate  = "lobby"
+	StatusUpdate = "status"
+	ErrorUpdate  = "error"
+)
+
+// Maximum number of consecutive bot moves, to avoid looping forever with bots
+// whose moves are not changing the game
+const maxBotActions = 5000
+
+// Update is the message pushed to players through their WebSocket connection.
+// Players get a status update every time the game changes.
+type Update struct {
+	Type  string     `json:"type"`
+	Seat  int        `json:"seat"`
+	Lobby *LobbyInfo `json:"lobby,omitempty"`
+	// Turn is the seat of the player who has to move, once the game has started
+	Turn int `json:"turn"`
+	// Status is the game as seen by the player, only sent once it has started
+	Status *bots.Status `json:"status,omitempty"`
+	// Standings are only sent once the game has ended
+	Standings []acquire.Standing `json:"standings,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// LobbyInfo describes a lobby without revealing players' tokens
+type LobbyInfo struct {
+	ID           string     `json:"id"`
+	State        string     `json:"state"`
+	HiddenAssets bool       `json:"hiddenAssets"`
+	Seats        []SeatInfo `json:"seats"`
+}
+
+// SeatInfo describes who is sitting at a seat
+type SeatInfo struct {
+	Name string `json:"name,omitempty"`
+	// Bot is the name of the bot playing the seat, if any
+	Bot    string `json:"bot,omitempty"`
+	Taken  bool   `json:"taken"`
+	Online bool   `json:"online"`
+}
+
+type seat struct {
+	name    string
+	token   string
+	botName string
+	bot     interfaces.Bot
+	conns   map[*wsConn]bool
+}
+
+func (s *seat) taken() bool {
+	return s.token != "" || s.bot != nil
+}
+
+// A lobby gathers players before a game starts and hosts it afterwards
+type lobby struct {
+	mu          sync.Mutex
+	id          string
+	state       string
+	seats       []*seat
+	hostToken   string
+	timeControl acquire.TimeControl
+	// hiddenAssets is true if players' cash and stock shares are kept secret
+	hiddenAssets bool
+	game         *acquire.SafeGame
+	players      []interfaces.Player
+	timer        *time.Timer
+	// moves counts the moves applied to the game, so bots can tell whether
+	// it changed while they were thinking
+	moves int
+	// advancing is true while bots are being played
+	advancing bool
+	// onFinish, if set, is called when the game ends
+	onFinish func()
+}
+
+func newLobby(id string, seats int, timeControl acquire.TimeControl, hiddenAssets bool) *lobby {
+	l := &lobby{
+		id:           id,
+		state:        WaitingState,
+		timeControl:  timeControl,
+		hiddenAssets: hiddenAssets,
+	}
+	for i := 0; i < seats; i++ {
+		l.seats = append(l.seats, &seat{conns: map[*wsConn]bool{}})
+	}
+	return l
+}
+
+// Sits a human player at the first free seat, returning its number and token
+func (l *lobby) join(name string) (int, string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.state != WaitingState {
+		return 0, "", errors.New(GameAlreadyStarted)
+	}
+	for i, s := range l.seats {
+		if !s.taken() {
+			token, err := newToken()
+			if err != nil {
+				return 0, "", err
+			}
+			s.name, s.token = name, token
+			if l.hostToken == "" {
+				l.hostToken = token
+			}
+			l.broadcast()
+			return i, token, nil
+		}
+	}
+	return 0, "", errors.New(LobbyFull)
+}
+
+// Starts the game, seating bots of the passed kind in the free seats
+func (l *lobby) start(token string, botName string, botCfg bots.Config) error {
+	if err := l.setUp(token, botName, botCfg); err != nil {
+		return err
+	}
+	l.advance()
+	return nil
+}
+
+// Creates the game and the bots playing it
+func (l *lobby) setUp(token string, botName string, botCfg bots.Config) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if token != l.hostToken {
+		return errors.New(NotHost)
+	}
+	if l.state != WaitingState {
+		return errors.New(GameAlreadyStarted)
+	}
+	seed := time.Now().UnixNano()
+	bts := make([]interfaces.Bot, len(l.seats))
+	for i, s := range l.seats {
+		if s.taken() {
+			continue
+		}
+		cfg := botCfg
+		if cfg.Seed == 0 {
+			cfg.Seed = bots.SeatSeed(seed, i)
+		}
+		bot, err := bots.Create(botName, cfg)
+		if err != nil {
+			return err
+		}
+		bts[i] = bot
+	}
+	players := make([]interfaces.Player, len(l.seats))
+	for i := range players {
+		players[i] = player.New()
+	}
+	game, err := acquire.New(players, acquire.Optional{Seed: seed, TimeControl: l.timeControl, HiddenAssets: l.hiddenAssets})
+	if err != nil {
+		return err
+	}
+	for i, s := range l.seats {
+		if bts[i] != nil {
+			s.name, s.botName, s.bot = botName, botName, bts[i]
+		}
+	}
+	l.game, l.players, l.state = acquire.NewSafe(game), players, PlayingState
+	return nil
+}
+
+// Applies the message sent by the player sitting at the passed seat
+func (l *lobby) play(number int, msg bots.Message) error {
+	l.mu.Lock()
+	if l.state != PlayingState {
+		l.mu.Unlock()
+		return errors.New(GameNotRunning)
+	}
+	err := l.game.Update(func(game *acquire.Game) error {
+		return bots.Apply(game, number, msg)
+	})
+	if err == nil {
+		l.moves++
+	}
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	l.advance()
+	return nil
+}
+
+// Plays the turns of bots until a human player has to move or the game ends, then
+// sends everybody the new status and sets the timer of the time control, if any.
+// Bots think without the lobby nor the game locked, so players can keep connecting
+// and reading the lobby meanwhile. Their moves are discarded if the game changed in
+// the meantime, for example because of a timeout. Only one call plays bots at a time:
+// if bots are already being played, this returns straight away, as that call will go on
+// with any turn left. Bots whose messages cannot be applied, or which panic, are deactivated.
+func (l *lobby) advance() {
+	l.mu.Lock()
+	if l.advancing {
+		l.mu.Unlock()
+		return
+	}
+	l.advancing = true
+	for i := 0; i < maxBotActions && l.state == PlayingState && l.isRunning(); i++ {
+		var st bots.Status
+		var err error
+		number, seen := -1, l.moves
+		l.game.Read(func(game *acquire.Game) {
+			if current := game.CurrentPlayerNumber(); l.seats[current].bot != nil {
+				number = current
+				st, err = bots.StatusFor(game, number)
+			}
+		})
+		if number == -1 {
+			break
+		}
+		bot := l.seats[number].bot
+		l.mu.Unlock()
+		var msg bots.Message
+		if err == nil {
+			msg, err = botMove(bot, st)
+		}
+		l.mu.Lock()
+		if l.moves != seen || l.state != PlayingState {
+			continue
+		}
+		l.game.Update(func(game *acquire.Game) error {
+			if err == nil {
+				err = bots.Apply(game, number, msg)
+			}
+			if err != nil {
+				game.DeactivatePlayer(l.players[number])
+			}
+			return nil
+		})
+		l.moves++
+	}
+	l.advancing = false
+	if l.state == PlayingState && !l.isRunning() {
+		l.state = FinishedState
+		if l.onFinish != nil {
+			l.onFinish()
+		}
+	}
+	l.setTimer()
+	l.broadcast()
+	l.mu.Unlock()
+}
+
+// Updates the passed bot with the status of the player it plays for and returns its
+// next move. Bots panicking are handled as if they returned an error.
+func botMove(bot interfaces.Bot, st bots.Status) (msg bots.Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("bot panicked: %v", r)
+		}
+	}()
+	bot.Update(st)
+	msg, ok := bot.Play().(bots.Message)
+	if failing, isFailing := bot.(interface{ Err() error }); isFailing && failing.Err() != nil {
+		return msg, failing.Err()
+	}
+	if !ok {
+		return msg, errors.New(bots.UnknownMessageType)
+	}
+	return msg, nil
+}
+
+func (l *lobby) isRunning() bool {
+	running := false
+	l.game.Read(func(game *acquire.Game) {
+		state := game.GameStateName()
+		running = state != interfaces.EndGameStateName && state != interfaces.InsufficientPlayersStateName
+	})
+	return running
+}
+
+// Schedules a timeout check for the deadline of the current move, if there are time controls
+func (l *lobby) setTimer() {
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	var deadline time.Time
+	var ok bool
+	l.game.Read(func(game *acquire.Game) {
+		deadline, ok = game.Deadline()
+	})
+	if ok {
+		l.timer = time.AfterFunc(time.Until(deadline), l.checkTimeout)
+	}
+}
+
+// Applies the time control fallback to the players who ran out of time
+func (l *lobby) checkTimeout() {
+	l.mu.Lock()
+	if l.state != PlayingState {
+		l.mu.Unlock()
+		return
+	}
+	for {
+		timedOut, err := l.game.CheckTimeout()
+		if !timedOut || err != nil {
+			break
+		}
+		l.moves++
+	}
+	l.mu.Unlock()
+	l.advance()
+}
+
+// Returns the number of the seat of the player with the passed token
+func (l *lobby) seatOf(token string) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, s := range l.seats {
+		if token != "" && s.token == token {
+			return i, nil
+		}
+	}
+	return 0, errors.New(InvalidToken)
+}
+
+// Registers a connection of the player at the passed seat and sends it the current update
+func (l *lobby) connect(number int, conn *wsConn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seats[number].conns[conn] = true
+	l.send(number, conn, l.update(number))
+}
+
+func (l *lobby) disconnect(number int, conn *wsConn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.seats[number].conns, conn)
+}
+
+// Sends an error update to a single connection
+func (l *lobby) sendError(number int, conn *wsConn, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.send(number, conn, Update{Type: ErrorUpdate, Seat: number, Error: err.Error()})
+}
+
+// Sends every connected player the update corresponding to their seat
+func (l *lobby) broadcast() {
+	for number, s := range l.seats {
+		if len(s.conns) == 0 {
+			continue
+		}
+		update := l.update(number)
+		for conn := range s.conns {
+			l.send(number, conn, update)
+		}
+	}
+}
+
+// Queues the passed update to be written to the connection, forgetting the connection
+// if it is closed or cannot keep up. Writes are not done here, as the lobby is locked.
+func (l *lobby) send(number int, conn *wsConn, update Update) {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return
+	}
+	if !conn.send(data) {
+		delete(l.seats[number].conns, conn)
+		go conn.close()
+	}
+}
+
+// Returns the update for the player at the passed seat
+func (l *lobby) update(number int) Update {
+	info := l.info()
+	update := Update{Type: LobbyUpdate, Seat: number, Lobby: &info}
+	if l.game == nil {
+		return update
+	}
+	update.Type = StatusUpdate
+	l.game.Read(func(game *acquire.Game) {
+		update.Turn = game.CurrentPlayerNumber()
+		if st, err := bots.StatusFor(game, number); err == nil {
+			update.Status = &st
+		}
+		if game.GameStateName() == interfaces.EndGameStateName {
+			update.Standings, _ = game.Results()
+		}
+	})
+	return update
+}
+
+func (l *lobby) info() LobbyInfo {
+	info := LobbyInfo{ID: l.id, State: l.state, HiddenAssets: l.hiddenAssets, Seats: []SeatInfo{}}
+	for _, s := range l.seats {
+		info.Seats = append(info.Seats, SeatInfo{
+			Name:   s.name,
+			Bot:    s.botName,
+			Taken:  s.taken(),
+			Online: len(s.conns) > 0,
+		})
+	}
+	return info
+}
+
+// Returns the lobby description, locking it
+func (l *lobby) describe() LobbyInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.info()
+}
@@ -0,0 +1,334 @@
+// Package server hosts Acquire games over HTTP. Players create and join lobbies
+// through a small JSON API and, once the game starts, play it through a WebSocket
+// connection, over which they receive their view of the game every time it changes
+// and send the same messages bots return (see bots.Message). Free seats are
+// taken by bots when the game starts. In hidden assets lobbies, players are not told
+// rivals' cash and stock shares. Lobbies are removed some time after their game ends,
+// or if their game is not started in time (see Config.FinishedLobbyTTL and Config.WaitingLobbyTTL).
+//
+// Endpoints:
+//
+//	GET  /lobbies                list lobbies
+//	POST /lobbies                create a lobby: {"name": "Ann", "seats": 4, "hiddenAssets": false}, the creator becomes its host
+//	GET  /lobbies/{id}           describe a lobby
+//	POST /lobbies/{id}/join      join a lobby: {"name": "Bob"}
+//	POST /lobbies/{id}/start     start the game: {"token": "host token", "bot": "greedy", "difficulty": "easy"}
+//	GET  /lobbies/{id}/ws?token= open the player's WebSocket connection
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/bots"
+)
+
+const (
+	// LobbyNotFound is an error message returned when the requested lobby does not exist
+	LobbyNotFound = "lobby_not_found"
+	// LobbyFull is an error message returned when joining a lobby without free seats
+	LobbyFull = "lobby_full"
+	// GameAlreadyStarted is an error message returned when joining or starting a lobby whose game has started
+	GameAlreadyStarted = "game_already_started"
+	// GameNotRunning is an error message returned when sending a move to a lobby whose game is not being played
+	GameNotRunning = "game_not_running"
+	// NotHost is an error message returned when somebody other than the host tries to start a game
+	NotHost = "not_host"
+	// InvalidToken is an error message returned when a token does not belong to any player of the lobby
+	InvalidToken = "invalid_token"
+	// WrongNumberSeats is an error message returned when creating a lobby not for 3 to 6 players
+	WrongNumberSeats = "wrong_number_seats"
+	// InvalidRequest is an error message returned when a request body cannot be decoded
+	InvalidRequest = "invalid_request"
+
+	// DefaultBot is the bot seated in free seats if the host does not choose one
+	DefaultBot = "greedy"
+	// DefaultFinishedLobbyTTL is the time lobbies are kept after their game ends if not configured
+	DefaultFinishedLobbyTTL = 10 * time.Minute
+	// DefaultWaitingLobbyTTL is the time lobbies can wait for their game to start if not configured
+	DefaultWaitingLobbyTTL = time.Hour
+)
+
+// Config holds the settings of a server
+type Config struct {
+	// TimeControl is applied to all games hosted by the server. By default there is none.
+	TimeControl acquire.TimeControl
+	// FinishedLobbyTTL is the time a lobby is kept after its game ends, so players can still
+	// get the results, before it is removed. If zero, DefaultFinishedLobbyTTL is used.
+	FinishedLobbyTTL time.Duration
+	// WaitingLobbyTTL is the time a lobby can wait for its game to start before it is
+	// removed. If zero, DefaultWaitingLobbyTTL is used.
+	WaitingLobbyTTL time.Duration
+}
+
+// Server hosts any number of concurrent lobbies. It implements http.Handler.
+type Server struct {
+	mu      sync.Mutex
+	cfg     Config
+	lobbies map[string]*lobby
+}
+
+// JoinResponse is returned to players when they create or join a lobby.
+// Token identifies the player in later requests and must be kept secret.
+type JoinResponse struct {
+	Lobby string `json:"lobby"`
+	Seat  int    `json:"seat"`
+	Token string `json:"token"`
+}
+
+type createRequest struct {
+	Name         string `json:"name"`
+	Seats        int    `json:"seats"`
+	HiddenAssets bool   `json:"hiddenAssets"`
+}
+
+type joinRequest struct {
+	Name string `json:"name"`
+}
+
+type startRequest struct {
+	Token      string `json:"token"`
+	Bot        string `json:"bot"`
+	Difficulty string `json:"difficulty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// New returns a new server with no lobbies
+func New(cfg Config) *Server {
+	if cfg.FinishedLobbyTTL == 0 {
+		cfg.FinishedLobbyTTL = DefaultFinishedLobbyTTL
+	}
+	if cfg.WaitingLobbyTTL == 0 {
+		cfg.WaitingLobbyTTL = DefaultWaitingLobbyTTL
+	}
+	return &Server{
+		cfg:     cfg,
+		lobbies: map[string]*lobby{},
+	}
+}
+
+// ServeHTTP routes requests to the lobbies API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "lobbies" || len(parts) > 3 {
+		http.NotFound(w, r)
+		return
+	}
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.list())
+		case http.MethodPost:
+			s.create(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	l, err := s.lobby(parts[1])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	action := ""
+	if len(parts) == 3 {
+		action = parts[2]
+	}
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, l.describe())
+	case action == "join" && r.Method == http.MethodPost:
+		s.join(w, r, l)
+	case action == "start" && r.Method == http.MethodPost:
+		s.start(w, r, l)
+	case action == "ws" && r.Method == http.MethodGet:
+		s.connect(w, r, l)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Returns the description of all lobbies, sorted by id
+func (s *Server) list() []LobbyInfo {
+	s.mu.Lock()
+	lobbies := make([]*lobby, 0, len(s.lobbies))
+	for _, l := range s.lobbies {
+		lobbies = append(lobbies, l)
+	}
+	s.mu.Unlock()
+	infos := []LobbyInfo{}
+	for _, l := range lobbies {
+		infos = append(infos, l.describe())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+func (s *Server) lobby(id string) (*lobby, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.lobbies[id]
+	if !ok {
+		return nil, errors.New(LobbyNotFound)
+	}
+	return l, nil
+}
+
+// Creates a lobby with an id not used by any other one and adds it to the server.
+// The lobby is removed some time after its game ends, or if it does not start in time.
+func (s *Server) add(seats int, hiddenAssets bool) (*lobby, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var id string
+	for id == "" || s.lobbies[id] != nil {
+		token, err := newToken()
+		if err != nil {
+			return nil, err
+		}
+		id = token[:8]
+	}
+	l := newLobby(id, seats, s.cfg.TimeControl, hiddenAssets)
+	l.onFinish = func() {
+		time.AfterFunc(s.cfg.FinishedLobbyTTL, func() { s.remove(id) })
+	}
+	time.AfterFunc(s.cfg.WaitingLobbyTTL, func() {
+		if l.describe().State == WaitingState {
+			s.remove(id)
+		}
+	})
+	s.lobbies[id] = l
+	return l, nil
+}
+
+func (s *Server) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.lobbies, id)
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.New(InvalidRequest))
+		return
+	}
+	if req.Seats < 3 || req.Seats > 6 {
+		writeError(w, errors.New(WrongNumberSeats))
+		return
+	}
+	l, err := s.add(req.Seats, req.HiddenAssets)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	number, token, err := l.join(req.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, JoinResponse{Lobby: l.id, Seat: number, Token: token})
+}
+
+func (s *Server) join(w http.ResponseWriter, r *http.Request, l *lobby) {
+	var req joinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.New(InvalidRequest))
+		return
+	}
+	number, token, err := l.join(req.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, JoinResponse{Lobby: l.id, Seat: number, Token: token})
+}
+
+func (s *Server) start(w http.ResponseWriter, r *http.Request, l *lobby) {
+	var req startRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.New(InvalidRequest))
+		return
+	}
+	if req.Bot == "" {
+		req.Bot = DefaultBot
+	}
+	if err := l.start(req.Token, req.Bot, bots.Config{Difficulty: req.Difficulty}); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, l.describe())
+}
+
+// Upgrades the request to a WebSocket connection for the player with the passed token,
+// and applies the messages received through it until it is closed
+func (s *Server) connect(w http.ResponseWriter, r *http.Request, l *lobby) {
+	number, err := l.seatOf(r.URL.Query().Get("token"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	conn, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+	l.connect(number, conn)
+	defer func() {
+		l.disconnect(number, conn)
+		conn.close()
+	}()
+	for {
+		data, err := conn.readMessage()
+		if err != nil {
+			return
+		}
+		var msg bots.Message
+		if err = json.Unmarshal(data, &msg); err == nil {
+			err = l.play(number, msg)
+		}
+		if err != nil {
+			l.sendError(number, conn, err)
+		}
+	}
+}
+
+// Returns a random hexadecimal string, used for lobby ids and player tokens
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Writes the passed error, choosing the HTTP status code from its message
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch err.Error() {
+	case LobbyNotFound:
+		status = http.StatusNotFound
+	case NotHost, InvalidToken:
+		status = http.StatusForbidden
+	case LobbyFull, GameAlreadyStarted:
+		status = http.StatusConflict
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
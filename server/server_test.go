@@ -0,0 +1,320 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/bots"
+	"github.com/svera/acquire/interfaces"
+)
+
+func postJSON(t *testing.T, url string, body interface{}, response interface{}) int {
+	data, _ := json.Marshal(body)
+	res, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Request to %s must succeed, got error %s", url, err)
+	}
+	defer res.Body.Close()
+	json.NewDecoder(res.Body).Decode(response)
+	return res.StatusCode
+}
+
+// Opens a WebSocket connection with the test server, as a client
+func dial(t *testing.T, srv *httptest.Server, path string) *wsConn {
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Connection to test server must succeed, got error %s", err)
+	}
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	req := "GET " + path + " HTTP/1.1\r\nHost: " + addr + "\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\nSec-WebSocket-Version: 13\r\n\r\n"
+	conn.Write([]byte(req))
+	reader := bufio.NewReader(conn)
+	res, err := http.ReadResponse(reader, nil)
+	if err != nil || res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("WebSocket handshake must succeed, got %v, %v", res, err)
+	}
+	if res.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Handshake must answer with the accept key defined by RFC 6455, got %s", res.Header.Get("Sec-WebSocket-Accept"))
+	}
+	return &wsConn{conn: conn, reader: reader, isClient: true}
+}
+
+func readUpdate(t *testing.T, conn *wsConn) Update {
+	conn.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	data, err := conn.readMessage()
+	if err != nil {
+		t.Fatalf("Update must be received, got error %s", err)
+	}
+	var update Update
+	if err = json.Unmarshal(data, &update); err != nil {
+		t.Fatalf("Update must be valid JSON, got error %s", err)
+	}
+	return update
+}
+
+func TestLobbyLifecycle(t *testing.T) {
+	srv := httptest.NewServer(New(Config{}))
+	defer srv.Close()
+
+	var host, guest JoinResponse
+	if status := postJSON(t, srv.URL+"/lobbies", createRequest{Name: "Ann", Seats: 3}, &host); status != http.StatusCreated {
+		t.Fatalf("Lobby must be created, got status %d", status)
+	}
+	postJSON(t, srv.URL+"/lobbies/"+host.Lobby+"/join", joinRequest{Name: "Bob"}, &guest)
+	if guest.Seat != 1 || guest.Token == "" || guest.Token == host.Token {
+		t.Errorf("Guest must get the second seat and its own token, got %v", guest)
+	}
+
+	var failure errorResponse
+	if status := postJSON(t, srv.URL+"/lobbies/"+host.Lobby+"/start", startRequest{Token: guest.Token}, &failure); status != http.StatusForbidden || failure.Error != NotHost {
+		t.Errorf("Only the host must be able to start the game, got status %d and error %s", status, failure.Error)
+	}
+	var info LobbyInfo
+	postJSON(t, srv.URL+"/lobbies/"+host.Lobby+"/start", startRequest{Token: host.Token, Bot: "random"}, &info)
+	if info.State == WaitingState || info.Seats[2].Bot != "random" {
+		t.Errorf("Game must start with a bot in the free seat, got %v", info)
+	}
+	if status := postJSON(t, srv.URL+"/lobbies/"+host.Lobby+"/join", joinRequest{Name: "Cid"}, &failure); status != http.StatusConflict {
+		t.Errorf("Joining a started game must fail with status %d, got %d", http.StatusConflict, status)
+	}
+	if status := postJSON(t, srv.URL+"/lobbies/nope/join", joinRequest{Name: "Cid"}, &failure); status != http.StatusNotFound {
+		t.Errorf("Joining an inexistent lobby must fail with status %d, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestPlayGameOverWebSocket(t *testing.T) {
+	srv := httptest.NewServer(New(Config{}))
+	defer srv.Close()
+	var host JoinResponse
+	postJSON(t, srv.URL+"/lobbies", createRequest{Name: "Ann", Seats: 4}, &host)
+	conn := dial(t, srv, "/lobbies/"+host.Lobby+"/ws?token="+host.Token)
+	defer conn.close()
+	if update := readUpdate(t, conn); update.Type != LobbyUpdate {
+		t.Fatalf("Players connected before the game starts must get a lobby update, got %s", update.Type)
+	}
+
+	var info LobbyInfo
+	postJSON(t, srv.URL+"/lobbies/"+host.Lobby+"/start", startRequest{Token: host.Token, Bot: "greedy"}, &info)
+
+	// The human player is played by a greedy bot too, through the WebSocket connection
+	player := bots.NewGreedy(bots.Config{Seed: 1})
+	for i := 0; i < 1000; i++ {
+		update := readUpdate(t, conn)
+		if update.Type == ErrorUpdate {
+			t.Fatalf("Moves must be accepted, got error %s", update.Error)
+		}
+		if update.Status == nil {
+			t.Fatalf("Status must be sent once the game has started")
+		}
+		if update.Status.State == interfaces.EndGameStateName {
+			if len(update.Standings) != 4 {
+				t.Errorf("Standings of all players must be sent when the game ends, got %v", update.Standings)
+			}
+			return
+		}
+		if update.Lobby.State == FinishedState {
+			t.Fatalf("Game must end normally, got state %s", update.Status.State)
+		}
+		// Updates are also received when bots move, but only the ones in which
+		// the human player has to move are answered
+		if update.Turn != update.Seat {
+			continue
+		}
+		player.Update(*update.Status)
+		data, _ := json.Marshal(player.Play())
+		conn.writeMessage(data)
+	}
+	t.Errorf("Game must end")
+}
+
+func TestInvalidMoveIsReported(t *testing.T) {
+	srv := httptest.NewServer(New(Config{}))
+	defer srv.Close()
+	var host JoinResponse
+	postJSON(t, srv.URL+"/lobbies", createRequest{Name: "Ann", Seats: 3}, &host)
+	conn := dial(t, srv, "/lobbies/"+host.Lobby+"/ws?token="+host.Token)
+	defer conn.close()
+	readUpdate(t, conn)
+
+	conn.writeMessage([]byte(`{"Type":"playTile","Params":{"Tile":"1A"}}`))
+	if update := readUpdate(t, conn); update.Type != ErrorUpdate || update.Error != GameNotRunning {
+		t.Errorf("Moves sent before the game starts must be answered with error %s, got %v", GameNotRunning, update)
+	}
+}
+
+func TestHiddenAssetsLobby(t *testing.T) {
+	srv := httptest.NewServer(New(Config{}))
+	defer srv.Close()
+	var host JoinResponse
+	postJSON(t, srv.URL+"/lobbies", createRequest{Name: "Ann", Seats: 3, HiddenAssets: true}, &host)
+	var info LobbyInfo
+	postJSON(t, srv.URL+"/lobbies/"+host.Lobby+"/start", startRequest{Token: host.Token}, &info)
+	if !info.HiddenAssets {
+		t.Errorf("Lobby must be described as having hidden assets")
+	}
+	conn := dial(t, srv, "/lobbies/"+host.Lobby+"/ws?token="+host.Token)
+	defer conn.close()
+	update := readUpdate(t, conn)
+	if update.Status == nil || !update.Status.HiddenAssets {
+		t.Fatalf("Status must be sent telling assets are hidden, got %v", update)
+	}
+	for _, rival := range update.Status.RivalsInfo {
+		if rival.Cash != 0 {
+			t.Errorf("Rivals' cash must not be sent in hidden assets games, got %d", rival.Cash)
+		}
+	}
+}
+
+func TestFinishedLobbyIsRemoved(t *testing.T) {
+	s := New(Config{FinishedLobbyTTL: time.Millisecond})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	var host JoinResponse
+	postJSON(t, srv.URL+"/lobbies", createRequest{Name: "Ann", Seats: 3}, &host)
+	var info LobbyInfo
+	postJSON(t, srv.URL+"/lobbies/"+host.Lobby+"/start", startRequest{Token: host.Token}, &info)
+
+	l, _ := s.lobby(host.Lobby)
+	l.mu.Lock()
+	l.game.Update(func(game *acquire.Game) error {
+		game.DeactivatePlayer(l.players[1])
+		return nil
+	})
+	l.mu.Unlock()
+	l.advance()
+
+	for i := 0; i < 100; i++ {
+		if _, err := s.lobby(host.Lobby); err != nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("Lobby must be removed once its game has finished")
+}
+
+func TestRunningLobbyIsKept(t *testing.T) {
+	s := New(Config{FinishedLobbyTTL: time.Millisecond})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	var host JoinResponse
+	postJSON(t, srv.URL+"/lobbies", createRequest{Name: "Ann", Seats: 3}, &host)
+	var info LobbyInfo
+	postJSON(t, srv.URL+"/lobbies/"+host.Lobby+"/start", startRequest{Token: host.Token}, &info)
+
+	time.Sleep(20 * time.Millisecond)
+	if _, err := s.lobby(host.Lobby); err != nil {
+		t.Errorf("Lobby must be kept while its game is running")
+	}
+}
+
+func TestSendDoesNotWaitForSlowClients(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+	conn := &wsConn{conn: local, queue: make(chan []byte, sendQueueSize), done: make(chan struct{})}
+	go conn.writeQueued()
+
+	// Nothing is read from the remote end, so the first message blocks the writer
+	// and the rest pile up in the queue
+	sent := 0
+	for conn.send([]byte("update")) {
+		sent++
+		if sent > sendQueueSize+1 {
+			t.Fatalf("Queue must not hold more than %d messages", sendQueueSize)
+		}
+	}
+	go io.Copy(io.Discard, remote)
+	conn.close()
+	if conn.send([]byte("update")) {
+		t.Errorf("Messages must not be queued once the connection is closed")
+	}
+}
+
+// Bot which waits to be released and then panics
+type panickingBot struct {
+	release chan struct{}
+}
+
+func (b *panickingBot) Update(st interface{}) {}
+
+func (b *panickingBot) Play() interface{} {
+	<-b.release
+	panic("broken bot")
+}
+
+func TestBotsThinkWithoutLockingTheLobby(t *testing.T) {
+	release := make(chan struct{})
+	bots.Register("panicking", "Waits and panics", func(cfg bots.Config) (interfaces.Bot, error) {
+		return &panickingBot{release: release}, nil
+	})
+	l := newLobby("test", 3, acquire.TimeControl{}, false)
+	started := make(chan error)
+	go func() {
+		started <- l.start("", "panicking", bots.Config{})
+	}()
+
+	described := make(chan LobbyInfo)
+	go func() {
+		for l.describe().State == WaitingState {
+			time.Sleep(time.Millisecond)
+		}
+		described <- l.describe()
+	}()
+	select {
+	case <-described:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Lobby must be readable while a bot is thinking")
+	}
+
+	close(release)
+	if err := <-started; err != nil {
+		t.Fatalf("Game must start, got error %s", err)
+	}
+	if info := l.describe(); info.State != FinishedState {
+		t.Errorf("Game must finish once panicking bots are deactivated, got state %s", info.State)
+	}
+}
+
+func TestWaitingLobbyExpires(t *testing.T) {
+	s := New(Config{WaitingLobbyTTL: time.Millisecond})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	var host JoinResponse
+	postJSON(t, srv.URL+"/lobbies", createRequest{Name: "Ann", Seats: 3}, &host)
+
+	for i := 0; i < 100; i++ {
+		if _, err := s.lobby(host.Lobby); err != nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("Lobby must be removed if its game is not started in time")
+}
+
+func TestLobbyIDsAreUnique(t *testing.T) {
+	s := New(Config{})
+	ids := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		l, err := s.add(3, false)
+		if err != nil {
+			t.Fatalf("Lobby must be added, got error %s", err)
+		}
+		if ids[l.id] {
+			t.Fatalf("Lobby id %s must not be reused", l.id)
+		}
+		ids[l.id] = true
+	}
+	if len(s.lobbies) != 100 {
+		t.Errorf("Expected %d lobbies, got %d", 100, len(s.lobbies))
+	}
+}
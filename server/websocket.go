@@ -0,0 +1,272 @@
+package server
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Minimal implementation of the WebSocket protocol (RFC 6455), just what is
+// needed to exchange JSON text messages with browsers and other clients.
+
+const (
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+
+	maxMessageSize = 1024 * 1024
+	// Time a write can take before the connection is considered lost
+	writeTimeout = 10 * time.Second
+	// Number of messages which can be waiting to be written to a connection
+	// before it is considered too slow and closed
+	sendQueueSize = 64
+)
+
+var (
+	errNotWebSocket   = errors.New("not a websocket handshake")
+	errUnmaskedFrame  = errors.New("unmasked frame from client")
+	errMessageTooLong = errors.New("message too long")
+)
+
+// A WebSocket connection. Reads must be done from a single goroutine,
+// while writes can be done from several ones.
+// Server side connections also have a queue of messages written in the background
+// (see send), so that senders are not blocked by slow clients.
+type wsConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+	// isClient is true on the client side of the connection, which must mask its frames
+	isClient  bool
+	queue     chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Upgrades the HTTP connection of the passed request to a WebSocket one
+func upgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" ||
+		key == "" {
+		http.Error(w, errNotWebSocket.Error(), http.StatusBadRequest)
+		return nil, errNotWebSocket
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be upgraded", http.StatusInternalServerError)
+		return nil, errNotWebSocket
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", acceptKey(key))
+	if err = rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c := &wsConn{
+		conn:   conn,
+		reader: rw.Reader,
+		queue:  make(chan []byte, sendQueueSize),
+		done:   make(chan struct{}),
+	}
+	go c.writeQueued()
+	return c, nil
+}
+
+// Returns the value of the Sec-WebSocket-Accept header for the passed handshake key
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Returns true if any of the comma separated values of the header is the passed token
+func headerContains(header http.Header, name string, token string) bool {
+	for _, value := range header[name] {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Reads the next data message, answering control frames meanwhile.
+// Returns io.EOF when the other side closes the connection.
+func (c *wsConn) readMessage() ([]byte, error) {
+	var message []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case opPing:
+			if err = c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+		case opPong:
+		case opClose:
+			c.writeFrame(opClose, nil)
+			return nil, io.EOF
+		case opText, opBinary, opContinuation:
+			if len(message)+len(payload) > maxMessageSize {
+				return nil, errMessageTooLong
+			}
+			message = append(message, payload...)
+			if fin {
+				return message, nil
+			}
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (bool, byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+	if !masked && !c.isClient {
+		return false, 0, nil, errUnmaskedFrame
+	}
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+	if length > maxMessageSize {
+		return false, 0, nil, errMessageTooLong
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// Sends the passed data as a text message
+func (c *wsConn) writeMessage(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	frame := []byte{0x80 | opcode}
+	var maskBit byte
+	if c.isClient {
+		maskBit = 0x80
+	}
+	length := len(payload)
+	switch {
+	case length < 126:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+	if c.isClient {
+		// Masking is meant to protect proxies, a fixed key is enough for the client used in tests
+		mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+		frame = append(frame, mask[:]...)
+		masked := make([]byte, length)
+		for i := range payload {
+			masked[i] = payload[i] ^ mask[i%4]
+		}
+		payload = masked
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := c.conn.Write(append(frame, payload...))
+	return err
+}
+
+// Queues the passed data to be sent as a text message, without waiting for it to be written.
+// Returns false if the message cannot be queued because the connection is closed or too
+// many messages are already waiting.
+func (c *wsConn) send(data []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	select {
+	case c.queue <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// Writes queued messages until the connection is closed, closing it if a write fails
+func (c *wsConn) writeQueued() {
+	for {
+		select {
+		case data := <-c.queue:
+			if err := c.writeMessage(data); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Sends a close frame and closes the underlying connection. Messages still queued are discarded.
+func (c *wsConn) close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		if c.done != nil {
+			close(c.done)
+		}
+		c.writeFrame(opClose, nil)
+		err = c.conn.Close()
+	})
+	return err
+}
@@ -0,0 +1,417 @@
+package acquire
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"time"
+
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/tile"
+	"github.com/svera/acquire/tileset"
+)
+
+const (
+	// SnapshotVersionNotSupported is an error returned when trying to restore a snapshot with an unknown format version
+	SnapshotVersionNotSupported = "snapshot_version_not_supported"
+	// InvalidSnapshot is an error returned when a snapshot holds data inconsistent with the game rules
+	InvalidSnapshot = "invalid_snapshot"
+	// TilesetNotEmpty is an error returned when restoring a snapshot on a tileset which already holds tiles
+	TilesetNotEmpty = "tileset_not_empty"
+
+	// SnapshotVersion is the version of the snapshot format produced by Game.Snapshot.
+	// It must be increased whenever fields are added, removed or change their meaning,
+	// as snapshots of other versions are not restored.
+	SnapshotVersion = 2
+
+	// Value used in Snapshot.Board to mark a cell holding an unincorporated tile
+	unincorporatedCell = -1
+)
+
+// Snapshot stores the whole state of a game in a serializable format,
+// so it can be persisted and restored later with Restore.
+// Corporations are referenced by their position in the corporations array
+// and tiles by their coordinates (see tile.Coords).
+type Snapshot struct {
+	Version int   `json:"version"`
+	Seed    int64 `json:"seed"`
+	// RandCalls and FallbackRandCalls are the number of values generated so far by the
+	// random generators of the game and of the time control fallback, both initialised with Seed
+	RandCalls         uint64 `json:"randCalls"`
+	FallbackRandCalls uint64 `json:"fallbackRandCalls,omitempty"`
+	State             string `json:"state"`
+	// Board maps the coordinates of every non empty cell to the index of the corporation
+	// which owns it, or -1 if the tile is unincorporated
+	Board               map[string]int         `json:"board"`
+	Players             []PlayerSnapshot       `json:"players"`
+	Corporations        [7]CorporationSnapshot `json:"corporations"`
+	Tileset             []string               `json:"tileset"`
+	CurrentPlayerNumber int                    `json:"currentPlayerNumber"`
+	InitialPlayerNumber int                    `json:"initialPlayerNumber"`
+	Round               int                    `json:"round"`
+	IsLastRound         bool                   `json:"isLastRound"`
+	NewCorpTiles        []string               `json:"newCorpTiles"`
+	OpeningTiles        []string               `json:"openingTiles,omitempty"`
+	DiscardedTiles      []string               `json:"discardedTiles,omitempty"`
+	MergeCorps          map[string][]int       `json:"mergeCorps"`
+	SellTradePlayers    []int                  `json:"sellTradePlayers"`
+	LastPlayedTile      string                 `json:"lastPlayedTile"`
+	FrozenPlayer        int                    `json:"frozenPlayer"`
+	DefunctIndex        int                    `json:"defunctIndex"`
+	// MergePrices holds the prices of the merging corporations, indexed by corporation,
+	// as they were when the merge started
+	MergePrices map[int]interfaces.Prices `json:"mergePrices,omitempty"`
+	// Actions holds the game log, so it keeps being replayable after a restore
+	Actions []Action `json:"actions,omitempty"`
+	// Results holds the final standings once the game has ended
+	Results []Standing `json:"results,omitempty"`
+	// HiddenAssets is true in games in which players' cash and stock shares are kept secret
+	HiddenAssets bool `json:"hiddenAssets,omitempty"`
+}
+
+// PlayerSnapshot stores the state of a player in a Snapshot
+type PlayerSnapshot struct {
+	Cash   int      `json:"cash"`
+	Shares [7]int   `json:"shares"`
+	Tiles  []string `json:"tiles"`
+	Active bool     `json:"active"`
+	// TimeUsed is the time spent by the player on their moves, when there are time controls
+	TimeUsed time.Duration `json:"timeUsed,omitempty"`
+}
+
+// CorporationSnapshot stores the state of a corporation in a Snapshot
+type CorporationSnapshot struct {
+	Size  int `json:"size"`
+	Stock int `json:"stock"`
+}
+
+// Used to encode snapshots with gob without calling MarshalBinary recursively
+type snapshotData Snapshot
+
+// MarshalBinary encodes the snapshot in a compact binary format
+func (s Snapshot) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(snapshotData(s)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a snapshot encoded with MarshalBinary
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode((*snapshotData)(s))
+}
+
+// Snapshot returns the current state of the game, including every player's hand, cash and
+// shares and the order of the tileset. It is meant to be persisted server side; use ViewFor
+// to send the game to players.
+func (g *Game) Snapshot() Snapshot {
+	snapshot := Snapshot{
+		Version:             SnapshotVersion,
+		Seed:                g.seed,
+		RandCalls:           g.randSource.calls,
+		FallbackRandCalls:   g.fallbackSource.calls,
+		State:               g.stateMachine.CurrentStateName(),
+		Board:               g.snapshotBoard(),
+		Tileset:             tilesToCoords(g.tileset.Tiles()),
+		CurrentPlayerNumber: g.currentPlayerNumber,
+		InitialPlayerNumber: g.initialPlayerNumber,
+		Round:               g.round,
+		IsLastRound:         g.isLastRound,
+		NewCorpTiles:        tilesToCoords(g.newCorpTiles),
+		OpeningTiles:        tilesToCoords(g.openingTiles),
+		DiscardedTiles:      tilesToCoords(g.discardedTiles),
+		MergeCorps:          map[string][]int{},
+		SellTradePlayers:    []int{},
+		Actions:             append([]Action{}, g.actions...),
+		Results:             g.results,
+		HiddenAssets:        g.hiddenAssets,
+	}
+	if g.lastPlayedTile != nil {
+		snapshot.LastPlayedTile = tile.Coords(g.lastPlayedTile)
+	}
+
+	for n, pl := range g.players {
+		ps := PlayerSnapshot{
+			Cash:     pl.Cash(),
+			Tiles:    tilesToCoords(pl.Tiles()),
+			Active:   pl.Active(),
+			TimeUsed: g.timeUsed[n],
+		}
+		for i, corp := range g.corporations {
+			ps.Shares[i] = pl.Shares(corp)
+		}
+		snapshot.Players = append(snapshot.Players, ps)
+	}
+
+	for i, corp := range g.corporations {
+		snapshot.Corporations[i] = CorporationSnapshot{Size: corp.Size(), Stock: corp.Stock()}
+	}
+
+	if g.merge != nil {
+		g.snapshotMerge(&snapshot)
+	}
+	return snapshot
+}
+
+// Returns the board in the format of Snapshot.Board
+func (g *Game) snapshotBoard() map[string]int {
+	cells := map[string]int{}
+	for number := 1; number < 13; number++ {
+		for _, letter := range tile.Letters {
+			cell := g.board.Cell(number, letter)
+			switch cell.Type() {
+			case interfaces.UnincorporatedOwner:
+				cells[tile.Coords(cell.(interfaces.Tile))] = unincorporatedCell
+			case interfaces.CorporationOwner:
+				cells[tile.Coords(tile.New(number, letter))] = g.corporationIndex(cell.(interfaces.Corporation))
+			}
+		}
+	}
+	return cells
+}
+
+func (g *Game) snapshotMerge(snapshot *Snapshot) {
+	for role, corps := range g.merge.corps {
+		snapshot.MergeCorps[role] = []int{}
+		for _, corp := range corps {
+			snapshot.MergeCorps[role] = append(snapshot.MergeCorps[role], g.corporationIndex(corp))
+		}
+	}
+	snapshot.SellTradePlayers = append(snapshot.SellTradePlayers, g.merge.sellTradePlayers...)
+	snapshot.FrozenPlayer = g.merge.mergemaker
+	snapshot.DefunctIndex = g.merge.defunctIndex
+	snapshot.MergePrices = map[int]interfaces.Prices{}
+	for corp, prices := range g.merge.prices {
+		snapshot.MergePrices[g.corporationIndex(corp)] = prices
+	}
+}
+
+// Restore rebuilds a game from a snapshot. Passed players, board and corporations must
+// be in their initial state, as returned by their respective New functions, as the snapshot
+// data is applied on top of them. If optional.Tileset is set, it must be empty, as returned
+// by tileset.NewWithTiles with no tiles, and the snapshot tiles are added to it.
+// Seed and HiddenAssets are taken from the snapshot, but the rest of optional fields are not
+// part of it: optional.TimeControl and optional.BonusRounding must be set again if the game
+// did not use the defaults, and so must optional.StateMachine if a custom one was used.
+func Restore(snapshot Snapshot, players []interfaces.Player, optional Optional) (*Game, error) {
+	if snapshot.Version != SnapshotVersion {
+		return nil, errors.New(SnapshotVersionNotSupported)
+	}
+	if len(players) != len(snapshot.Players) {
+		return nil, errors.New(WrongNumberPlayers)
+	}
+	if optional.Tileset != nil && len(optional.Tileset.Tiles()) > 0 {
+		return nil, errors.New(TilesetNotEmpty)
+	}
+	tiles, err := coordsToTiles(snapshot.Tileset)
+	if err != nil {
+		return nil, err
+	}
+	optional.Seed = snapshot.Seed
+	optional.HiddenAssets = snapshot.HiddenAssets
+	gm, err := newGame(players, optional)
+	if err != nil {
+		return nil, err
+	}
+	gm.randSource.skipTo(snapshot.RandCalls)
+	gm.fallbackSource.skipTo(snapshot.FallbackRandCalls)
+	if optional.Tileset == nil {
+		gm.tileset = tileset.NewWithTiles(tiles, gm.rand)
+	} else {
+		gm.tileset.Add(tiles)
+	}
+	if err = gm.restoreCorporations(snapshot); err != nil {
+		return nil, err
+	}
+	if err = gm.restorePlayers(snapshot); err != nil {
+		return nil, err
+	}
+	if err = gm.restoreBoard(snapshot); err != nil {
+		return nil, err
+	}
+	if err = gm.restoreMerge(snapshot); err != nil {
+		return nil, err
+	}
+	if err = restoreState(gm.stateMachine, snapshot.State); err != nil {
+		return nil, err
+	}
+	if !gm.isValidPlayerNumber(snapshot.CurrentPlayerNumber) || !gm.isValidPlayerNumber(snapshot.InitialPlayerNumber) {
+		return nil, errors.New(InvalidSnapshot)
+	}
+	gm.currentPlayerNumber = snapshot.CurrentPlayerNumber
+	gm.initialPlayerNumber = snapshot.InitialPlayerNumber
+	gm.round = snapshot.Round
+	gm.isLastRound = snapshot.IsLastRound
+	gm.actions = append([]Action{}, snapshot.Actions...)
+	gm.results = snapshot.Results
+	if gm.newCorpTiles, err = coordsToTiles(snapshot.NewCorpTiles); err != nil {
+		return nil, err
+	}
+	if gm.openingTiles, err = coordsToTiles(snapshot.OpeningTiles); err != nil {
+		return nil, err
+	}
+	if gm.discardedTiles, err = coordsToTiles(snapshot.DiscardedTiles); err != nil {
+		return nil, err
+	}
+	if snapshot.LastPlayedTile != "" {
+		if gm.lastPlayedTile, err = tile.Parse(snapshot.LastPlayedTile); err != nil {
+			return nil, err
+		}
+	}
+	return gm, nil
+}
+
+func (g *Game) restoreCorporations(snapshot Snapshot) error {
+	for i, cs := range snapshot.Corporations {
+		if cs.Size < 0 || cs.Stock < 0 {
+			return errors.New(InvalidSnapshot)
+		}
+		corp := g.corporations[i]
+		corp.Grow(cs.Size)
+		if diff := corp.Stock() - cs.Stock; diff > 0 {
+			corp.RemoveStock(diff)
+		} else {
+			corp.AddStock(-diff)
+		}
+	}
+	return nil
+}
+
+func (g *Game) restorePlayers(snapshot Snapshot) error {
+	for i, ps := range snapshot.Players {
+		pl := g.players[i]
+		if diff := pl.Cash() - ps.Cash; diff > 0 {
+			pl.RemoveCash(diff)
+		} else {
+			pl.AddCash(-diff)
+		}
+		for j, amount := range ps.Shares {
+			if amount > 0 {
+				pl.AddShares(g.corporations[j], amount)
+			}
+		}
+		tiles, err := coordsToTiles(ps.Tiles)
+		if err != nil {
+			return err
+		}
+		for _, tl := range tiles {
+			pl.PickTile(tl)
+		}
+		if !ps.Active {
+			pl.Deactivate()
+		}
+		g.timeUsed[i] = ps.TimeUsed
+	}
+	return nil
+}
+
+func (g *Game) restoreBoard(snapshot Snapshot) error {
+	for coords, owner := range snapshot.Board {
+		tl, err := tile.Parse(coords)
+		if err != nil {
+			return err
+		}
+		if owner != unincorporatedCell && !isValidCorporationIndex(owner) {
+			return errors.New(InvalidSnapshot)
+		}
+		g.board.PutTile(tl)
+		if owner != unincorporatedCell {
+			g.board.SetOwner(g.corporations[owner], []interfaces.Tile{tl})
+		}
+	}
+	return nil
+}
+
+// Rebuilds the merge in progress, if any. Snapshots without merge prices get
+// them frozen from the restored corporations, as their sizes do not change until
+// the merge is completed.
+func (g *Game) restoreMerge(snapshot Snapshot) error {
+	if len(snapshot.MergeCorps) == 0 {
+		return nil
+	}
+	mergeCorps := map[string][]interfaces.Corporation{}
+	for role, indexes := range snapshot.MergeCorps {
+		mergeCorps[role] = []interfaces.Corporation{}
+		for _, index := range indexes {
+			if !isValidCorporationIndex(index) {
+				return errors.New(InvalidSnapshot)
+			}
+			mergeCorps[role] = append(mergeCorps[role], g.corporations[index])
+		}
+	}
+	for _, number := range snapshot.SellTradePlayers {
+		if !g.isValidPlayerNumber(number) {
+			return errors.New(InvalidSnapshot)
+		}
+	}
+	if !g.isValidPlayerNumber(snapshot.FrozenPlayer) {
+		return errors.New(InvalidSnapshot)
+	}
+	if snapshot.DefunctIndex < 0 || snapshot.DefunctIndex > len(mergeCorps["defunct"]) {
+		return errors.New(InvalidSnapshot)
+	}
+	g.merge = newMerge(mergeCorps, snapshot.FrozenPlayer)
+	g.merge.sellTradePlayers = append([]int{}, snapshot.SellTradePlayers...)
+	g.merge.defunctIndex = snapshot.DefunctIndex
+	for index, prices := range snapshot.MergePrices {
+		if !isValidCorporationIndex(index) {
+			return errors.New(InvalidSnapshot)
+		}
+		g.merge.prices[g.corporations[index]] = prices
+	}
+	return nil
+}
+
+// Takes a state machine in its initial state to the passed state
+// through valid transitions
+func restoreState(stateMachine interfaces.StateMachine, name string) error {
+	switch name {
+	case interfaces.PlayTileStateName:
+	case interfaces.FoundCorpStateName:
+		stateMachine.ToFoundCorp()
+	case interfaces.UntieMergeStateName:
+		stateMachine.ToUntieMerge()
+	case interfaces.SellTradeStateName:
+		stateMachine.ToSellTrade()
+	case interfaces.BuyStockStateName:
+		stateMachine.ToBuyStock()
+	case interfaces.EndGameStateName:
+		stateMachine.ToBuyStock()
+		stateMachine.ToEndGame()
+	case interfaces.InsufficientPlayersStateName:
+		stateMachine.ToInsufficientPlayers()
+	}
+	if stateMachine.CurrentStateName() != name {
+		return errors.New(InvalidSnapshot)
+	}
+	return nil
+}
+
+func (g *Game) isValidPlayerNumber(number int) bool {
+	return number >= 0 && number < len(g.players)
+}
+
+func isValidCorporationIndex(index int) bool {
+	return index >= 0 && index < totalCorporations
+}
+
+func tilesToCoords(tiles []interfaces.Tile) []string {
+	coords := []string{}
+	for _, tl := range tiles {
+		coords = append(coords, tile.Coords(tl))
+	}
+	return coords
+}
+
+func coordsToTiles(coords []string) ([]interfaces.Tile, error) {
+	tiles := []interfaces.Tile{}
+	for _, c := range coords {
+		tl, err := tile.Parse(c)
+		if err != nil {
+			return nil, err
+		}
+		tiles = append(tiles, tl)
+	}
+	return tiles, nil
+}
@@ -0,0 +1,167 @@
+package acquire
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/tile"
+	"github.com/svera/acquire/tileset"
+)
+
+func TestSnapshotRestoreJSON(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 7})
+	playTurns(game, 40)
+	snapshot := game.Snapshot()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("Snapshot must be encodable to JSON, got error %s", err)
+	}
+	var decoded Snapshot
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Snapshot must be decodable from JSON, got error %s", err)
+	}
+	restored, err := Restore(decoded, newDefaultPlayers(3), Optional{})
+	if err != nil {
+		t.Fatalf("Snapshot must be restorable, got error %s", err)
+	}
+	if !reflect.DeepEqual(snapshot, restored.Snapshot()) {
+		t.Errorf("Restored game state differs from the original one")
+	}
+	if restored.GameStateName() != game.GameStateName() {
+		t.Errorf("Restored game must be in state %s, got %s", game.GameStateName(), restored.GameStateName())
+	}
+}
+
+func TestSnapshotRestoreBinary(t *testing.T) {
+	game, _ := New(newDefaultPlayers(4), Optional{Seed: 11})
+	playTurns(game, 25)
+	snapshot := game.Snapshot()
+
+	data, err := snapshot.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Snapshot must be encodable to binary, got error %s", err)
+	}
+	var decoded Snapshot
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Snapshot must be decodable from binary, got error %s", err)
+	}
+	restored, err := Restore(decoded, newDefaultPlayers(4), Optional{})
+	if err != nil {
+		t.Fatalf("Snapshot must be restorable, got error %s", err)
+	}
+	expected, _ := json.Marshal(snapshot)
+	got, _ := json.Marshal(restored.Snapshot())
+	if string(expected) != string(got) {
+		t.Errorf("Restored game state differs from the original one")
+	}
+}
+
+// A restored game must go on exactly as the original one would have, drawing the same tiles
+func TestRestoredGameContinuesAsOriginal(t *testing.T) {
+	game, _ := New(newDefaultPlayers(4), Optional{Seed: 13})
+	playTurns(game, 30)
+	restored, err := Restore(game.Snapshot(), newDefaultPlayers(4), Optional{})
+	if err != nil {
+		t.Fatalf("Snapshot must be restorable, got error %s", err)
+	}
+	playTurns(game, 40)
+	playTurns(restored, 40)
+
+	expected, _ := json.Marshal(game.Snapshot())
+	got, _ := json.Marshal(restored.Snapshot())
+	if string(expected) != string(got) {
+		t.Errorf("Restored game must continue as the original one")
+	}
+	replayed, err := Replay(restored.Log())
+	if err != nil {
+		t.Fatalf("Log of the restored game must be replayable, got error %s", err)
+	}
+	if replayedJSON, _ := json.Marshal(replayed.Snapshot()); string(replayedJSON) != string(got) {
+		t.Errorf("Replaying the log of the restored game must lead to the same state")
+	}
+}
+
+func TestRestoreKeepsDiscardedTiles(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 7})
+	discarded := game.Player(0).Tiles()[0]
+	game.Player(0).DiscardTile(discarded)
+	game.discardedTiles = append(game.discardedTiles, discarded)
+
+	restored, err := Restore(game.Snapshot(), newDefaultPlayers(3), Optional{})
+	if err != nil {
+		t.Fatalf("Snapshot must be restorable, got error %s", err)
+	}
+	if !reflect.DeepEqual(tilesToCoords(restored.DiscardedTiles()), []string{tile.Coords(discarded)}) {
+		t.Errorf("Restored game must keep discarded tile %s, got %v", tile.Coords(discarded), tilesToCoords(restored.DiscardedTiles()))
+	}
+}
+
+func TestRestoreWrongVersion(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{})
+	snapshot := game.Snapshot()
+	snapshot.Version = SnapshotVersion + 1
+
+	if _, err := Restore(snapshot, newDefaultPlayers(3), Optional{}); err == nil || err.Error() != SnapshotVersionNotSupported {
+		t.Errorf("Restoring a snapshot with an unknown version must return error %s", SnapshotVersionNotSupported)
+	}
+}
+
+func TestRestoreOnTileset(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 3})
+	playTurns(game, 10)
+	snapshot := game.Snapshot()
+
+	if _, err := Restore(snapshot, newDefaultPlayers(3), Optional{Tileset: tileset.New(nil)}); err == nil || err.Error() != TilesetNotEmpty {
+		t.Errorf("Restoring a snapshot on a non empty tileset must return error %s, got %v", TilesetNotEmpty, err)
+	}
+	ts := tileset.NewWithTiles(nil, nil)
+	if _, err := Restore(snapshot, newDefaultPlayers(3), Optional{Tileset: ts}); err != nil {
+		t.Fatalf("Snapshot must be restorable on an empty tileset, got error %s", err)
+	}
+	if len(ts.Tiles()) != len(snapshot.Tileset) {
+		t.Errorf("Tileset must hold the %d snapshot tiles, got %d", len(snapshot.Tileset), len(ts.Tiles()))
+	}
+}
+
+func TestRestoreInvalidBoard(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{})
+	snapshot := game.Snapshot()
+	snapshot.Board["5E"] = 9
+
+	if _, err := Restore(snapshot, newDefaultPlayers(3), Optional{}); err == nil || err.Error() != InvalidSnapshot {
+		t.Errorf("Restoring a snapshot with an unknown corporation must return error %s", InvalidSnapshot)
+	}
+}
+
+// Plays the passed number of actions taking always the first available option
+func playTurns(game *Game, actions int) {
+	for i := 0; i < actions; i++ {
+		switch game.GameStateName() {
+		case interfaces.PlayTileStateName:
+			for _, tl := range game.CurrentPlayer().Tiles() {
+				if game.IsTilePlayable(tl) {
+					game.PlayTile(tl)
+					break
+				}
+			}
+		case interfaces.FoundCorpStateName:
+			for _, corp := range game.Corporations() {
+				if !corp.IsActive() {
+					game.FoundCorporation(corp)
+					break
+				}
+			}
+		case interfaces.BuyStockStateName:
+			game.BuyStock(map[interfaces.Corporation]int{})
+		case interfaces.SellTradeStateName:
+			game.SellTrade(map[interfaces.Corporation]int{}, map[interfaces.Corporation]int{})
+		case interfaces.UntieMergeStateName:
+			game.UntieMerge(game.TiedCorps()[0])
+		default:
+			return
+		}
+	}
+}
@@ -5,9 +5,20 @@
 package tile
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/svera/acquire/interfaces"
 )
 
+const (
+	// InvalidCoords is an error returned when trying to parse a string which is not a valid tile coordinate
+	InvalidCoords = "invalid_coords"
+)
+
+// Letters holds all valid tile letters, in board order
+var Letters = [9]string{"A", "B", "C", "D", "E", "F", "G", "H", "I"}
+
 // Tile stores position and owner of a tile
 type Tile struct {
 	number int
@@ -33,3 +44,27 @@ func (t *Tile) Letter() string {
 func (t *Tile) Type() string {
 	return interfaces.UnincorporatedOwner
 }
+
+// Coords returns the coordinates of the passed tile as a string, that is,
+// its number followed by its letter (for example "5E")
+func Coords(t interfaces.Tile) string {
+	return strconv.Itoa(t.Number()) + t.Letter()
+}
+
+// Parse returns a Tile instance from coordinates in the format returned by Coords
+func Parse(coords string) (*Tile, error) {
+	if len(coords) < 2 {
+		return nil, errors.New(InvalidCoords)
+	}
+	number, err := strconv.Atoi(coords[:len(coords)-1])
+	if err != nil || number < 1 || number > 12 {
+		return nil, errors.New(InvalidCoords)
+	}
+	letter := coords[len(coords)-1:]
+	for _, valid := range Letters {
+		if letter == valid {
+			return New(number, letter), nil
+		}
+	}
+	return nil, errors.New(InvalidCoords)
+}
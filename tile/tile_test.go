@@ -0,0 +1,21 @@
+package tile
+
+import "testing"
+
+func TestCoords(t *testing.T) {
+	if Coords(New(12, "I")) != "12I" {
+		t.Errorf("Tile coordinates must be %s, got %s", "12I", Coords(New(12, "I")))
+	}
+}
+
+func TestParse(t *testing.T) {
+	tl, err := Parse("5E")
+	if err != nil || tl.Number() != 5 || tl.Letter() != "E" {
+		t.Errorf("Coordinates 5E must be parsed as tile 5E")
+	}
+	for _, coords := range []string{"", "E", "0A", "13A", "5J", "AE"} {
+		if _, err := Parse(coords); err == nil {
+			t.Errorf("Invalid coordinates %s must return error", coords)
+		}
+	}
+}
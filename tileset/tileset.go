@@ -18,11 +18,17 @@ const (
 // Tileset stores all tiles used in game
 type Tileset struct {
 	tiles []interfaces.Tile
+	rn    *rand.Rand
 }
 
-// New initialises and returns a Tileset instance
-func New() *Tileset {
-	tileset := Tileset{}
+// New initialises and returns a Tileset instance. Tiles are drawn using the passed
+// random generator, so the same seed always yields the same tile order. If rn is nil
+// a generator seeded with the current time is used.
+func New(rn *rand.Rand) *Tileset {
+	if rn == nil {
+		rn = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	tileset := Tileset{rn: rn}
 	letters := [9]string{"A", "B", "C", "D", "E", "F", "G", "H", "I"}
 	for number := 1; number < 13; number++ {
 		for _, letter := range letters {
@@ -33,21 +39,29 @@ func New() *Tileset {
 	return &tileset
 }
 
+// NewWithTiles initialises and returns a Tileset instance holding only the passed tiles,
+// drawn using the passed random generator (see New)
+func NewWithTiles(tiles []interfaces.Tile, rn *rand.Rand) *Tileset {
+	if rn == nil {
+		rn = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &Tileset{
+		tiles: append([]interfaces.Tile{}, tiles...),
+		rn:    rn,
+	}
+}
+
 // Draw extracts a random tile from the tileset and returns it
 func (t *Tileset) Draw() (interfaces.Tile, error) {
-	source := rand.NewSource(time.Now().UnixNano())
-	rn := rand.New(source)
 	remainingTiles := len(t.tiles)
 	if remainingTiles == 0 {
 		return &tile.Tile{}, errors.New(NoTilesAvailable)
 	}
 
-	var pos int
-	if remainingTiles > 1 {
-		pos = rn.Intn(remainingTiles - 1)
-	} else if remainingTiles == 1 {
-		pos = 0
+	if t.rn == nil {
+		t.rn = rand.New(rand.NewSource(time.Now().UnixNano()))
 	}
+	pos := t.rn.Intn(remainingTiles)
 	tile := t.tiles[pos]
 	t.tiles = append(t.tiles[:pos], t.tiles[pos+1:]...)
 	return tile, nil
@@ -68,3 +82,8 @@ func (t *Tileset) Add(tiles []interfaces.Tile) interfaces.Tileset {
 	t.tiles = append(t.tiles, tiles...)
 	return t
 }
+
+// Tiles returns the tiles remaining in the tileset
+func (t *Tileset) Tiles() []interfaces.Tile {
+	return t.tiles
+}
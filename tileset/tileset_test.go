@@ -1,6 +1,7 @@
 package tileset
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/svera/acquire/interfaces"
@@ -8,14 +9,14 @@ import (
 )
 
 func TestNewTileSet(t *testing.T) {
-	tileset := New()
+	tileset := New(nil)
 	if len(tileset.tiles) != 108 {
 		t.Errorf("Tileset must have exactly 108 tiles, got %d", len(tileset.tiles))
 	}
 }
 
 func TestDraw(t *testing.T) {
-	tileset := New()
+	tileset := New(nil)
 	tileset.Draw()
 	if len(tileset.tiles) != 107 {
 		t.Errorf("Tile must be extracted from tileset")
@@ -40,3 +41,15 @@ func TestDrawFromEmptyTileset(t *testing.T) {
 		t.Errorf("Trying to get a tile from an empty tileset must return an error")
 	}
 }
+
+func TestDrawIsDeterministicWithSameSeed(t *testing.T) {
+	tileset1 := New(rand.New(rand.NewSource(42)))
+	tileset2 := New(rand.New(rand.NewSource(42)))
+	for i := 0; i < 108; i++ {
+		tl1, _ := tileset1.Draw()
+		tl2, _ := tileset2.Draw()
+		if tl1.Number() != tl2.Number() || tl1.Letter() != tl2.Letter() {
+			t.Fatalf("Tilesets with the same seed must draw tiles in the same order, got %d%s and %d%s at draw %d", tl1.Number(), tl1.Letter(), tl2.Number(), tl2.Letter(), i)
+		}
+	}
+}
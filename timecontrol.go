@@ -0,0 +1,159 @@
+package acquire
+
+import (
+	"errors"
+	"time"
+
+	"github.com/svera/acquire/interfaces"
+)
+
+// Fallbacks applied when a player runs out of time
+const (
+	// FallbackAutoPlay makes the simplest move for the player: placing a random playable tile,
+	// founding a random corporation, not buying stock, holding defunct stock or
+	// choosing the first tied corporation in a merge
+	FallbackAutoPlay = "autoPlay"
+	// FallbackDeactivate deactivates the player, as DeactivatePlayer does
+	FallbackDeactivate = "deactivate"
+)
+
+// UnknownFallback is an error returned when the time control fallback is not supported
+const UnknownFallback = "unknown_fallback"
+
+// Clock tells the current time. It can be replaced in tests to control the time flow.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (c systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// TimeControl sets how much time players have to move. Every action of a player is
+// a move, and the clock of a player starts when they get the turn or make their previous move.
+// Time controls are not enforced by the game on their own: whoever drives it must call
+// CheckTimeout when the deadline returned by Deadline is reached.
+type TimeControl struct {
+	// PerMove is the time a player has for every move. If zero, there is no limit per move.
+	PerMove time.Duration
+	// PerGame is the time a player has for all their moves in the game. If zero, there is no limit.
+	PerGame time.Duration
+	// Fallback is applied when a player runs out of time. If empty, FallbackAutoPlay is used.
+	// Once a player's game budget is exhausted, the fallback is applied to all their moves.
+	Fallback string
+	// Clock is used to measure time. If nil, the system clock is used.
+	Clock Clock
+}
+
+// Returns true if any time limit is set
+func (tc TimeControl) enabled() bool {
+	return tc.PerMove > 0 || tc.PerGame > 0
+}
+
+// Fills the default values of the time control, checking they are valid
+func initTimeControl(tc TimeControl) (TimeControl, error) {
+	if tc.Fallback == "" {
+		tc.Fallback = FallbackAutoPlay
+	}
+	if tc.Fallback != FallbackAutoPlay && tc.Fallback != FallbackDeactivate {
+		return tc, errors.New(UnknownFallback)
+	}
+	if tc.Clock == nil {
+		tc.Clock = systemClock{}
+	}
+	return tc, nil
+}
+
+// Charges the time elapsed since the last move to the passed player and restarts the move clock
+func (g *Game) chargeTime(playerNumber int) {
+	now := g.timeControl.Clock.Now()
+	if g.isValidPlayerNumber(playerNumber) {
+		g.timeUsed[playerNumber] += now.Sub(g.moveStartedAt)
+	}
+	g.moveStartedAt = now
+}
+
+// TimeUsed returns the time the player with the passed number has spent on their moves so far,
+// not including the move in progress
+func (g *Game) TimeUsed(playerNumber int) time.Duration {
+	if !g.isValidPlayerNumber(playerNumber) {
+		return 0
+	}
+	return g.timeUsed[playerNumber]
+}
+
+// Deadline returns the moment in which the current player runs out of time for their move.
+// The second value is false if the game has no time limits or it is not running.
+func (g *Game) Deadline() (time.Time, bool) {
+	if !g.timeControl.enabled() || !g.isRunning() {
+		return time.Time{}, false
+	}
+	left := g.timeControl.PerMove
+	if g.timeControl.PerGame > 0 {
+		budget := g.timeControl.PerGame - g.timeUsed[g.currentPlayerNumber]
+		if left == 0 || budget < left {
+			left = budget
+		}
+	}
+	return g.moveStartedAt.Add(left), true
+}
+
+// CheckTimeout applies the time control fallback if the current player has run out of time,
+// returning true in that case. As the fallback may leave the turn to the same player, for example
+// to buy stock after placing a tile, it should be called again until it returns false.
+func (g *Game) CheckTimeout() (bool, error) {
+	deadline, ok := g.Deadline()
+	if !ok || g.timeControl.Clock.Now().Before(deadline) {
+		return false, nil
+	}
+	ev := newEvent(PlayerTimedOutEvent)
+	ev.Player = g.currentPlayerNumber
+	g.emit(ev)
+	if g.timeControl.Fallback == FallbackDeactivate {
+		g.DeactivatePlayer(g.CurrentPlayer())
+		return true, nil
+	}
+	return true, g.autoPlay()
+}
+
+// Makes the simplest move for the current player in the current state
+func (g *Game) autoPlay() error {
+	switch g.stateMachine.CurrentStateName() {
+	case interfaces.PlayTileStateName:
+		playable := []interfaces.Tile{}
+		for _, tl := range g.CurrentPlayer().Tiles() {
+			if g.IsTilePlayable(tl) {
+				playable = append(playable, tl)
+			}
+		}
+		if len(playable) == 0 {
+			return errors.New(TileTemporaryUnplayable)
+		}
+		return g.PlayTile(playable[g.fallbackRand.Intn(len(playable))])
+	case interfaces.FoundCorpStateName:
+		inactive := g.findCorporationsByActiveState(false)
+		return g.FoundCorporation(inactive[g.fallbackRand.Intn(len(inactive))])
+	case interfaces.BuyStockStateName:
+		return g.BuyStock(map[interfaces.Corporation]int{})
+	case interfaces.SellTradeStateName:
+		_, err := g.SellTrade(map[interfaces.Corporation]int{}, map[interfaces.Corporation]int{})
+		return err
+	case interfaces.UntieMergeStateName:
+		return g.UntieMerge(g.TiedCorps()[0])
+	}
+	return errors.New(ActionNotAllowed)
+}
+
+// Returns true if the game has neither ended nor stopped for lack of players
+func (g *Game) isRunning() bool {
+	state := g.stateMachine.CurrentStateName()
+	return state != interfaces.EndGameStateName && state != interfaces.InsufficientPlayersStateName
+}
+
+// Returns the source of the generator used to choose random moves when a player runs out of time,
+// which is kept apart from the game one so timeouts do not change the tiles drawn afterwards
+func newFallbackSource(seed int64) *countingSource {
+	return newCountingSource(seed + 1)
+}
@@ -0,0 +1,114 @@
+package acquire
+
+import (
+	"testing"
+	"time"
+
+	"github.com/svera/acquire/interfaces"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTimedGame(t *testing.T, tc TimeControl) (*Game, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tc.Clock = clock
+	game, err := New(newDefaultPlayers(4), Optional{Seed: 1, TimeControl: tc})
+	if err != nil {
+		t.Fatalf("Game with time control must be created, got error %s", err)
+	}
+	return game, clock
+}
+
+func TestNoDeadlineWithoutTimeControl(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 1})
+	if _, ok := game.Deadline(); ok {
+		t.Errorf("Game without time control must not have a deadline")
+	}
+	if timedOut, _ := game.CheckTimeout(); timedOut {
+		t.Errorf("Players must not time out in a game without time control")
+	}
+}
+
+func TestUnknownFallback(t *testing.T) {
+	if _, err := New(newDefaultPlayers(3), Optional{TimeControl: TimeControl{PerMove: time.Second, Fallback: "panic"}}); err == nil || err.Error() != UnknownFallback {
+		t.Errorf("Game creation must fail with error %s when the fallback is not supported", UnknownFallback)
+	}
+}
+
+func TestTimeoutAutoPlaysTile(t *testing.T) {
+	game, clock := newTimedGame(t, TimeControl{PerMove: 10 * time.Second})
+	player := game.CurrentPlayerNumber()
+	clock.advance(9 * time.Second)
+	if timedOut, _ := game.CheckTimeout(); timedOut {
+		t.Fatalf("Player must not time out before the deadline")
+	}
+
+	clock.advance(time.Second)
+	if timedOut, err := game.CheckTimeout(); !timedOut || err != nil {
+		t.Fatalf("Player must time out at the deadline, got error %v", err)
+	}
+	actions := game.Log().Actions
+	if len(actions) != 1 || actions[0].Type != PlayTileAction || actions[0].Player != player {
+		t.Fatalf("A tile must be placed on behalf of the player who timed out, got %v", actions)
+	}
+	if game.TimeUsed(player) != 10*time.Second {
+		t.Errorf("Time spent on the move must be charged to the player, expected %s, got %s", 10*time.Second, game.TimeUsed(player))
+	}
+	if deadline, _ := game.Deadline(); !deadline.Equal(clock.now.Add(10 * time.Second)) {
+		t.Errorf("Move clock must restart after the move, got deadline %s", deadline)
+	}
+}
+
+func TestTimeoutWithGameBudget(t *testing.T) {
+	game, clock := newTimedGame(t, TimeControl{PerMove: 10 * time.Second, PerGame: 15 * time.Second})
+	player := game.CurrentPlayerNumber()
+	clock.advance(8 * time.Second)
+	var playable interfaces.Tile
+	for _, tl := range game.CurrentPlayer().Tiles() {
+		if game.IsTilePlayable(tl) {
+			playable = tl
+		}
+	}
+	game.PlayTile(playable)
+	if game.TimeUsed(player) != 8*time.Second {
+		t.Errorf("Time spent on the move must be charged to the player, expected %s, got %s", 8*time.Second, game.TimeUsed(player))
+	}
+
+	game.timeUsed[game.CurrentPlayerNumber()] = 8 * time.Second
+	if deadline, _ := game.Deadline(); !deadline.Equal(clock.now.Add(7 * time.Second)) {
+		t.Errorf("Deadline must be limited by the time left in the game budget, got %s", deadline)
+	}
+}
+
+func TestTimeoutDeactivatesPlayer(t *testing.T) {
+	game, clock := newTimedGame(t, TimeControl{PerMove: 10 * time.Second, Fallback: FallbackDeactivate})
+	player := game.CurrentPlayerNumber()
+	timedOut := []int{}
+	game.Subscribe(func(ev Event) {
+		if ev.Type == PlayerTimedOutEvent {
+			timedOut = append(timedOut, ev.Player)
+		}
+	})
+	clock.advance(11 * time.Second)
+	game.CheckTimeout()
+
+	if game.Player(player).Active() {
+		t.Errorf("Player who timed out must be deactivated")
+	}
+	if game.CurrentPlayerNumber() == player || game.GameStateName() != interfaces.PlayTileStateName {
+		t.Errorf("Turn must pass to the next player, got player %d in state %s", game.CurrentPlayerNumber(), game.GameStateName())
+	}
+	if len(timedOut) != 1 || timedOut[0] != player {
+		t.Errorf("Event %s must be emitted for the player who timed out, got %v", PlayerTimedOutEvent, timedOut)
+	}
+}
@@ -0,0 +1,294 @@
+// Package tournament plays series of bot games and rates the bots taking part in them
+package tournament
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/svera/acquire/runner"
+)
+
+// Tournament formats
+const (
+	// RoundRobin plays a table for every combination of entrants, once per seating rotation
+	RoundRobin = "roundRobin"
+	// Swiss pairs entrants with similar scores at every round
+	Swiss = "swiss"
+)
+
+const (
+	// UnknownFormat is an error message returned when the tournament format is not supported
+	UnknownFormat = "unknown_format"
+	// WrongNumberSeats is an error message returned when tables are not for 3 to 6 players
+	WrongNumberSeats = "wrong_number_seats"
+	// NotEnoughEntrants is an error message returned when there are less entrants than seats per table
+	NotEnoughEntrants = "not_enough_entrants"
+
+	// InitialRating is the rating every entrant starts the tournament with
+	InitialRating = 1500
+	// ratingFactor is the maximum amount of rating points an entrant can win or lose in a game
+	ratingFactor = 32
+)
+
+// Config holds the settings of a tournament
+type Config struct {
+	// Entrants holds the bot names taking part in the tournament, as accepted by bots.Create.
+	// The same bot can enter several times.
+	Entrants []string
+	Format   string
+	// Seats is the number of players of every table
+	Seats int
+	// Rounds is the number of times the whole round robin schedule is played,
+	// or the number of rounds of a Swiss tournament
+	Rounds int
+	// Seed of the first game. Every game is played with a different seed derived from it.
+	// If zero, a seed based on the current time is used.
+	Seed int64
+	// Workers is the number of games played at the same time. If zero, the number of CPUs is used.
+	Workers int
+	// MaxActions is passed to the runner for every game
+	MaxActions int
+}
+
+// EntrantStats stores the performance of an entrant along the tournament
+type EntrantStats struct {
+	Entrant int
+	Bot     string
+	// Games counts finished games, including the ones in which the entrant was deactivated,
+	// which count as lost with no cash
+	Games int
+	// Wins counts shared victories as a fraction of a win
+	Wins        float64
+	WinRate     float64
+	AverageCash float64
+	Rating      float64
+	// Unfinished counts games which did not reach their end, which are not rated
+	Unfinished int
+	Faults     int
+	totalCash  int
+}
+
+// Report stores the outcome of a tournament
+type Report struct {
+	Games int
+	// Standings holds the stats of all entrants, sorted by rating
+	Standings []EntrantStats
+}
+
+// A table is a game to be played, with the entrant sitting at every seat
+type table struct {
+	seed   int64
+	seats  []int
+	result runner.Result
+	err    error
+}
+
+// Run plays the configured tournament and returns its report
+func Run(cfg Config) (Report, error) {
+	if cfg.Seats < 3 || cfg.Seats > 6 {
+		return Report{}, errors.New(WrongNumberSeats)
+	}
+	if len(cfg.Entrants) < cfg.Seats {
+		return Report{}, errors.New(NotEnoughEntrants)
+	}
+	if cfg.Rounds == 0 {
+		cfg.Rounds = 1
+	}
+	if cfg.Workers == 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	stats := make([]EntrantStats, len(cfg.Entrants))
+	for i, name := range cfg.Entrants {
+		stats[i] = EntrantStats{Entrant: i, Bot: name, Rating: InitialRating}
+	}
+
+	var played []*table
+	switch cfg.Format {
+	case RoundRobin:
+		played = roundRobinSchedule(len(cfg.Entrants), cfg.Seats, cfg.Rounds, cfg.Seed)
+		play(cfg, played)
+		rate(stats, played)
+	case Swiss:
+		for round := 0; round < cfg.Rounds; round++ {
+			tables := swissRound(stats, cfg.Seats, round, cfg.Seed+int64(len(played)))
+			play(cfg, tables)
+			rate(stats, tables)
+			played = append(played, tables...)
+		}
+	default:
+		return Report{}, errors.New(UnknownFormat)
+	}
+
+	report := Report{Games: len(played), Standings: stats}
+	sort.SliceStable(report.Standings, func(i, j int) bool {
+		return report.Standings[i].Rating > report.Standings[j].Rating
+	})
+	return report, nil
+}
+
+// Returns the tables for every combination of entrants, each one repeated
+// with every rotation of its seats, the whole schedule being played the passed number of rounds
+func roundRobinSchedule(entrants int, seats int, rounds int, seed int64) []*table {
+	tables := []*table{}
+	for round := 0; round < rounds; round++ {
+		for _, combination := range combinations(entrants, seats) {
+			for rotation := 0; rotation < seats; rotation++ {
+				tables = append(tables, &table{
+					seed:  seed + int64(len(tables)),
+					seats: rotate(combination, rotation),
+				})
+			}
+		}
+	}
+	return tables
+}
+
+// Returns the tables of a Swiss round, grouping entrants with similar wins
+// and ratings. Entrants left when there are not enough to fill a table get a bye.
+func swissRound(stats []EntrantStats, seats int, round int, seed int64) []*table {
+	ranking := make([]int, len(stats))
+	for i := range ranking {
+		ranking[i] = i
+	}
+	sort.SliceStable(ranking, func(i, j int) bool {
+		a, b := stats[ranking[i]], stats[ranking[j]]
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return a.Rating > b.Rating
+	})
+	tables := []*table{}
+	for start := 0; start+seats <= len(ranking); start += seats {
+		tables = append(tables, &table{
+			seed:  seed + int64(len(tables)),
+			seats: rotate(ranking[start:start+seats], round%seats),
+		})
+	}
+	return tables
+}
+
+// Plays all passed tables, using as many goroutines as configured workers
+func play(cfg Config, tables []*table) {
+	jobs := make(chan *table)
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				names := make([]string, len(t.seats))
+				for seat, entrant := range t.seats {
+					names[seat] = cfg.Entrants[entrant]
+				}
+				t.result, t.err = runner.Run(runner.Config{Bots: names, Seed: t.seed, MaxActions: cfg.MaxActions})
+			}
+		}()
+	}
+	for _, t := range tables {
+		jobs <- t
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// Updates entrants stats with the results of the passed tables, in schedule order
+// so ratings do not depend on which games finished first
+func rate(stats []EntrantStats, tables []*table) {
+	for _, t := range tables {
+		for _, fault := range t.result.Faults {
+			stats[t.seats[fault.Player]].Faults++
+		}
+		if t.err != nil {
+			for _, entrant := range t.seats {
+				stats[entrant].Unfinished++
+			}
+			continue
+		}
+		// Deactivated players are not in the standings, they are ranked last
+		// and finish with no cash, as their assets are returned when deactivated
+		ranks := make([]int, len(t.seats))
+		for i, entrant := range t.seats {
+			ranks[i] = len(t.seats)
+			stats[entrant].Games++
+		}
+		for _, standing := range t.result.Standings {
+			entrant := t.seats[standing.Player]
+			ranks[standing.Player] = standing.Rank
+			stats[entrant].totalCash += standing.Cash
+			if standing.Rank == 1 {
+				stats[entrant].Wins += 1 / float64(len(t.result.Winners))
+			}
+		}
+		updateRatings(stats, t.seats, ranks)
+	}
+	for i := range stats {
+		if stats[i].Games > 0 {
+			stats[i].WinRate = stats[i].Wins / float64(stats[i].Games)
+			stats[i].AverageCash = float64(stats[i].totalCash) / float64(stats[i].Games)
+		}
+	}
+}
+
+// Updates Elo ratings of the entrants sitting at a table, handling the game
+// as a set of matches between every pair of them
+func updateRatings(stats []EntrantStats, seats []int, ranks []int) {
+	deltas := make([]float64, len(seats))
+	for i := range seats {
+		for j := range seats {
+			if i == j {
+				continue
+			}
+			score := 0.5
+			if ranks[i] < ranks[j] {
+				score = 1
+			} else if ranks[i] > ranks[j] {
+				score = 0
+			}
+			expected := expectedScore(stats[seats[i]].Rating, stats[seats[j]].Rating)
+			deltas[i] += ratingFactor * (score - expected) / float64(len(seats)-1)
+		}
+	}
+	for i, entrant := range seats {
+		stats[entrant].Rating += deltas[i]
+	}
+}
+
+// Returns the probability of an entrant with rating a beating one with rating b
+func expectedScore(a float64, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Returns all combinations of size k of the numbers from 0 to n-1, in lexicographic order
+func combinations(n int, k int) [][]int {
+	result := [][]int{}
+	combination := make([]int, k)
+	var fill func(pos int, start int)
+	fill = func(pos int, start int) {
+		if pos == k {
+			result = append(result, append([]int{}, combination...))
+			return
+		}
+		for i := start; i <= n-(k-pos); i++ {
+			combination[pos] = i
+			fill(pos+1, i+1)
+		}
+	}
+	fill(0, 0)
+	return result
+}
+
+// Returns a copy of the passed seats, shifted to the left the passed number of positions
+func rotate(seats []int, shift int) []int {
+	rotated := make([]int, len(seats))
+	for i := range seats {
+		rotated[i] = seats[(i+shift)%len(seats)]
+	}
+	return rotated
+}
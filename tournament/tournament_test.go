@@ -0,0 +1,122 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/svera/acquire"
+	"github.com/svera/acquire/runner"
+)
+
+func TestRoundRobin(t *testing.T) {
+	report, err := Run(Config{Entrants: []string{"random", "random", "random", "random"}, Format: RoundRobin, Seats: 3, Seed: 1})
+	if err != nil {
+		t.Fatalf("Tournament must be played, got error %s", err)
+	}
+	// 4 combinations of 3 entrants, 3 seat rotations each
+	if report.Games != 12 {
+		t.Errorf("Round robin must have %d games, got %d", 12, report.Games)
+	}
+	for _, st := range report.Standings {
+		if st.Games+st.Unfinished != 9 {
+			t.Errorf("Every entrant must play %d games, entrant %d played %d", 9, st.Entrant, st.Games+st.Unfinished)
+		}
+	}
+	for i := 1; i < len(report.Standings); i++ {
+		if report.Standings[i-1].Rating < report.Standings[i].Rating {
+			t.Errorf("Standings must be sorted by rating")
+		}
+	}
+}
+
+func TestSwiss(t *testing.T) {
+	report, err := Run(Config{Entrants: []string{"random", "random", "random", "random", "random", "random", "random"}, Format: Swiss, Seats: 3, Rounds: 2, Seed: 1})
+	if err != nil {
+		t.Fatalf("Tournament must be played, got error %s", err)
+	}
+	// 7 entrants fill 2 tables per round, one of them gets a bye
+	if report.Games != 4 {
+		t.Errorf("Swiss tournament must have %d games, got %d", 4, report.Games)
+	}
+}
+
+func TestWrongConfig(t *testing.T) {
+	tests := []struct {
+		cfg      Config
+		expected string
+	}{
+		{Config{Entrants: []string{"random", "random", "random"}, Format: "knockout", Seats: 3}, UnknownFormat},
+		{Config{Entrants: []string{"random", "random", "random"}, Format: RoundRobin, Seats: 7}, WrongNumberSeats},
+		{Config{Entrants: []string{"random", "random"}, Format: RoundRobin, Seats: 3}, NotEnoughEntrants},
+	}
+	for _, tt := range tests {
+		if _, err := Run(tt.cfg); err == nil || err.Error() != tt.expected {
+			t.Errorf("Expected error %s, got %v", tt.expected, err)
+		}
+	}
+}
+
+func TestUpdateRatings(t *testing.T) {
+	stats := []EntrantStats{{Rating: InitialRating}, {Rating: InitialRating}, {Rating: InitialRating}}
+	updateRatings(stats, []int{0, 1, 2}, []int{1, 2, 2})
+
+	if stats[0].Rating != InitialRating+16 || stats[1].Rating != InitialRating-8 || stats[2].Rating != InitialRating-8 {
+		t.Errorf("Winner must gain what the rest lose, got ratings %v, %v and %v", stats[0].Rating, stats[1].Rating, stats[2].Rating)
+	}
+}
+
+func TestFaultyBotsAreRankedLast(t *testing.T) {
+	stats := []EntrantStats{{Rating: InitialRating}, {Rating: InitialRating}, {Rating: InitialRating}}
+	tables := []*table{{
+		seats: []int{0, 1, 2},
+		result: runner.Result{
+			Standings: []acquire.Standing{{Player: 1, Rank: 1}, {Player: 2, Rank: 2}},
+			Winners:   []int{1},
+			Faults:    []runner.Fault{{Player: 0}},
+		},
+	}}
+	rate(stats, tables)
+
+	if stats[0].Rating >= InitialRating || stats[0].Rating >= stats[2].Rating {
+		t.Errorf("Deactivated bot must be rated below the rest, got ratings %v, %v and %v", stats[0].Rating, stats[1].Rating, stats[2].Rating)
+	}
+}
+
+func TestDeactivatedGamesCount(t *testing.T) {
+	stats := []EntrantStats{{Rating: InitialRating}, {Rating: InitialRating}, {Rating: InitialRating}}
+	finished := func(winner int, deactivated int) *table {
+		standings := []acquire.Standing{}
+		for player := 0; player < 3; player++ {
+			if player == winner {
+				standings = append(standings, acquire.Standing{Player: player, Rank: 1, Cash: 9000})
+			} else if player != deactivated {
+				standings = append(standings, acquire.Standing{Player: player, Rank: 2, Cash: 3000})
+			}
+		}
+		return &table{
+			seats:  []int{0, 1, 2},
+			result: runner.Result{Standings: standings, Winners: []int{winner}, Faults: []runner.Fault{{Player: deactivated}}},
+		}
+	}
+	rate(stats, []*table{finished(0, 1), finished(1, 2)})
+
+	if stats[1].Games != 2 || stats[1].WinRate != 0.5 || stats[1].AverageCash != 4500 {
+		t.Errorf("Game in which the entrant was deactivated must be counted as lost with no cash, got %d games, %v win rate and %v average cash", stats[1].Games, stats[1].WinRate, stats[1].AverageCash)
+	}
+}
+
+func TestTournamentIsReproducible(t *testing.T) {
+	cfg := Config{Entrants: []string{"random", "greedy", "random", "greedy"}, Format: RoundRobin, Seats: 3, Seed: 5, Workers: 2}
+	first, _ := Run(cfg)
+	second, _ := Run(cfg)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Tournaments played with the same seed must have the same report")
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	expected := [][]int{{0, 1, 2}, {0, 1, 3}, {0, 2, 3}, {1, 2, 3}}
+	if got := combinations(4, 3); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected combinations %v, got %v", expected, got)
+	}
+}
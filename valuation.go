@@ -0,0 +1,107 @@
+package acquire
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/svera/acquire/interfaces"
+)
+
+// Valuation holds the worth of a player at the current point of the game,
+// as if the game ended right now
+type Valuation struct {
+	Player int `json:"player"`
+	// Rank follows the same tie policy as Standing.Rank, applied to NetWorth
+	Rank int `json:"rank"`
+	Cash int `json:"cash"`
+	// SharesValue is the value of the player's stock shares of active corporations at current prices
+	SharesValue int `json:"sharesValue"`
+	// Bonuses is the amount of majority and minority bonuses the player would receive
+	// from all active corporations, leaving out the ones already paid in a merge in progress
+	Bonuses  int `json:"bonuses"`
+	NetWorth int `json:"netWorth"`
+}
+
+// Valuations returns the current worth of all active players, ordered by rank
+// and, within the same rank, by player number. Game state is not modified.
+// As valuations are based on everybody's cash and shares, this is meant for server
+// side use, such as bots and analysis tools; use ValuationsFor to show them to players.
+func (g *Game) Valuations() []Valuation {
+	valuations := make([]Valuation, len(g.players))
+	for number, pl := range g.players {
+		valuations[number] = Valuation{Player: number, Cash: pl.Cash()}
+	}
+	for _, corp := range g.activeCorporations() {
+		if !g.areBonusesPaid(corp) {
+			for number, amount := range g.bonuses(corp, currentPrices(corp)) {
+				valuations[number].Bonuses += amount
+			}
+		}
+		for number, pl := range g.players {
+			valuations[number].SharesValue += pl.Shares(corp) * corp.StockPrice()
+		}
+	}
+
+	active := []Valuation{}
+	for number, pl := range g.players {
+		if !pl.Active() {
+			continue
+		}
+		valuation := valuations[number]
+		valuation.NetWorth = valuation.Cash + valuation.SharesValue + valuation.Bonuses
+		active = append(active, valuation)
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].NetWorth > active[j].NetWorth
+	})
+	netWorths := make([]int, len(active))
+	for i := range active {
+		netWorths[i] = active[i].NetWorth
+	}
+	for i, rank := range competitionRanks(netWorths) {
+		active[i].Rank = rank
+	}
+	return active
+}
+
+// ValuationsFor returns the valuations the player with the passed number is allowed to see.
+// In open assets games, these are the same ones returned by Valuations. In hidden assets
+// games, only the player's own valuation is returned, without Bonuses nor Rank, as they depend
+// on rivals' shares, so its NetWorth is just the sum of the player's cash and shares value.
+func (g *Game) ValuationsFor(playerNumber int) ([]Valuation, error) {
+	if !g.isValidPlayerNumber(playerNumber) {
+		return nil, errors.New(PlayerNotFound)
+	}
+	if !g.hiddenAssets {
+		return g.Valuations(), nil
+	}
+	pl := g.players[playerNumber]
+	if !pl.Active() {
+		return []Valuation{}, nil
+	}
+	valuation := Valuation{Player: playerNumber, Cash: pl.Cash()}
+	for _, corp := range g.activeCorporations() {
+		valuation.SharesValue += pl.Shares(corp) * corp.StockPrice()
+	}
+	valuation.NetWorth = valuation.Cash + valuation.SharesValue
+	return []Valuation{valuation}, nil
+}
+
+// Returns true if the passed corporation is a defunct of the merge in progress whose
+// bonuses have already been paid, which happens once its stockholders start selling,
+// trading or holding their shares
+func (g *Game) areBonusesPaid(corp interfaces.Corporation) bool {
+	if g.merge == nil {
+		return false
+	}
+	paid := g.merge.defunctIndex
+	if g.stateMachine.CurrentStateName() == interfaces.SellTradeStateName {
+		paid++
+	}
+	for i, defunct := range g.merge.corps["defunct"] {
+		if i < paid && defunct == corp {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package acquire
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/mocks"
+	"github.com/svera/acquire/tile"
+)
+
+func TestValuations(t *testing.T) {
+	players, optional := setup()
+	optional.Corporations[0].Grow(2)
+	optional.Corporations[0].(*mocks.Corporation).FakeIsActive = true
+	optional.Corporations[0].(*mocks.Corporation).FakeStockPrice = 200
+	optional.Corporations[0].(*mocks.Corporation).FakeMajorityBonus = 2000
+	optional.Corporations[0].(*mocks.Corporation).FakeMinorityBonus = 1000
+	// Shares of inactive corporations are worthless
+	optional.Corporations[1].(*mocks.Corporation).FakeStockPrice = 300
+	players[0].AddShares(optional.Corporations[0], 3)
+	players[1].AddShares(optional.Corporations[0], 1)
+	players[2].AddShares(optional.Corporations[1], 5)
+	game, _ := New(players, optional)
+
+	valuations := game.Valuations()
+
+	expected := []Valuation{
+		{Player: 0, Rank: 1, Cash: 6000, SharesValue: 600, Bonuses: 2000, NetWorth: 8600},
+		{Player: 1, Rank: 2, Cash: 6000, SharesValue: 200, Bonuses: 1000, NetWorth: 7200},
+		{Player: 2, Rank: 3, Cash: 6000, SharesValue: 0, Bonuses: 0, NetWorth: 6000},
+	}
+	for i := range expected {
+		if valuations[i] != expected[i] {
+			t.Errorf("Expected valuation %v, got %v", expected[i], valuations[i])
+		}
+	}
+	if players[0].Cash() != 6000 {
+		t.Errorf("Valuations must not modify player cash, expected %d, got %d", 6000, players[0].Cash())
+	}
+}
+
+func TestValuationsForHiddenAssets(t *testing.T) {
+	players, optional := setup()
+	optional.Corporations[0].Grow(2)
+	optional.Corporations[0].(*mocks.Corporation).FakeIsActive = true
+	optional.Corporations[0].(*mocks.Corporation).FakeStockPrice = 200
+	optional.Corporations[0].(*mocks.Corporation).FakeMajorityBonus = 2000
+	players[0].AddShares(optional.Corporations[0], 3)
+	players[1].AddShares(optional.Corporations[0], 1)
+	optional.HiddenAssets = true
+	game, _ := New(players, optional)
+
+	valuations, err := game.ValuationsFor(1)
+	expected := Valuation{Player: 1, Cash: 6000, SharesValue: 200, NetWorth: 6200}
+	if err != nil || len(valuations) != 1 || valuations[0] != expected {
+		t.Errorf("Expected only valuation %v, got %v and error %v", expected, valuations, err)
+	}
+	if _, err = game.ValuationsFor(3); err == nil || err.Error() != PlayerNotFound {
+		t.Errorf("Valuations for an inexistent player must fail with error %s, got %v", PlayerNotFound, err)
+	}
+}
+
+func TestValuationsForOpenAssets(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 7})
+	playTurns(game, 20)
+	valuations, err := game.ValuationsFor(1)
+	if err != nil || !reflect.DeepEqual(valuations, game.Valuations()) {
+		t.Errorf("Valuations of all players must be returned in open assets games, got %v and error %v", valuations, err)
+	}
+}
+
+// Bonuses paid when a merge starts must not be counted again while stockholders
+// sell, trade or hold their defunct shares
+func TestValuationsDuringMerge(t *testing.T) {
+	game, _ := New(newDefaultPlayers(4), Optional{Seed: 1})
+	corps := game.Corporations()
+	putCorporation(game, corps[2], "2E", "3E", "4E", "5E")
+	putCorporation(game, corps[0], "7E", "8E", "9E")
+	putCorporation(game, corps[1], "6C", "6D")
+	game.currentPlayerNumber = 0
+	mergeTile := tile.New(6, "E")
+	game.Player(0).PickTile(mergeTile)
+	game.Player(0).AddShares(corps[0], 1).AddShares(corps[1], 1)
+	game.Player(1).AddShares(corps[0], 2)
+	game.Player(2).AddShares(corps[1], 3)
+
+	before := netWorths(game)
+	game.PlayTile(mergeTile)
+	if game.GameStateName() != interfaces.SellTradeStateName {
+		t.Fatalf("Game must be in state %s, got %s", interfaces.SellTradeStateName, game.GameStateName())
+	}
+	if during := netWorths(game); !reflect.DeepEqual(before, during) {
+		t.Errorf("Net worths must not change when bonuses are paid, expected %v, got %v", before, during)
+	}
+	for game.CurrentDefunct() == corps[0] {
+		game.SellTrade(map[interfaces.Corporation]int{}, map[interfaces.Corporation]int{})
+	}
+	if during := netWorths(game); !reflect.DeepEqual(before, during) {
+		t.Errorf("Net worths must not change when the next defunct is dealt with, expected %v, got %v", before, during)
+	}
+}
+
+// Returns the net worth of every active player, indexed by player number
+func netWorths(game *Game) map[int]int {
+	worths := map[int]int{}
+	for _, valuation := range game.Valuations() {
+		worths[valuation.Player] = valuation.NetWorth
+	}
+	return worths
+}
@@ -0,0 +1,116 @@
+package acquire
+
+import (
+	"errors"
+
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/tile"
+)
+
+const (
+	// PlayerNotFound is an error returned when asking for a player number which is not in the game
+	PlayerNotFound = "player_not_found"
+	// CorporationNotFound is an error returned when asking about a corporation which is not in the game
+	CorporationNotFound = "corporation_not_found"
+)
+
+// View is the game as seen by one of its players, suitable for sending to clients.
+// Unlike Snapshot, it does not reveal the tiles in rivals' hands nor the order of the
+// tileset, and in hidden assets games it does not reveal rivals' cash and stock shares either.
+// Corporations are referenced by their position in the corporations array
+// and tiles by their coordinates (see tile.Coords).
+type View struct {
+	// Player is the number of the player the view is for
+	Player int    `json:"player"`
+	State  string `json:"state"`
+	// Board maps the coordinates of every non empty cell to the index of the corporation
+	// which owns it, or -1 if the tile is unincorporated
+	Board               map[string]int         `json:"board"`
+	Players             []PlayerView           `json:"players"`
+	Corporations        [7]CorporationSnapshot `json:"corporations"`
+	TilesLeft           int                    `json:"tilesLeft"`
+	CurrentPlayerNumber int                    `json:"currentPlayerNumber"`
+	Round               int                    `json:"round"`
+	IsLastRound         bool                   `json:"isLastRound"`
+	LastPlayedTile      string                 `json:"lastPlayedTile,omitempty"`
+	HiddenAssets        bool                   `json:"hiddenAssets"`
+	// Acquirer and CurrentDefunct are the indexes of the corporations taking part in the
+	// merge in progress, or -1 if there is none
+	Acquirer       int   `json:"acquirer"`
+	CurrentDefunct int   `json:"currentDefunct"`
+	TiedCorps      []int `json:"tiedCorps"`
+}
+
+// PlayerView stores what a player can see of another one, or of themselves, in a View.
+// Tiles is only set for the player the view is for, while Cash and Shares are nil
+// for rivals in hidden assets games.
+type PlayerView struct {
+	Tiles     []string `json:"tiles,omitempty"`
+	TileCount int      `json:"tileCount"`
+	Cash      *int     `json:"cash,omitempty"`
+	Shares    *[7]int  `json:"shares,omitempty"`
+	Active    bool     `json:"active"`
+}
+
+// HiddenAssets returns true if players' cash and stock shares are kept secret in this game
+func (g *Game) HiddenAssets() bool {
+	return g.hiddenAssets
+}
+
+// BankShares returns how many stock shares of the passed corporation are still unpurchased.
+// Rules allow any player to ask for it at any time, even if assets are hidden.
+func (g *Game) BankShares(corp interfaces.Corporation) (int, error) {
+	if g.corporationIndex(corp) == -1 {
+		return 0, errors.New(CorporationNotFound)
+	}
+	return corp.Stock(), nil
+}
+
+// ViewFor returns the game as seen by the player with the passed number
+func (g *Game) ViewFor(playerNumber int) (View, error) {
+	if !g.isValidPlayerNumber(playerNumber) {
+		return View{}, errors.New(PlayerNotFound)
+	}
+	view := View{
+		Player:              playerNumber,
+		State:               g.stateMachine.CurrentStateName(),
+		Board:               g.snapshotBoard(),
+		TilesLeft:           len(g.tileset.Tiles()),
+		CurrentPlayerNumber: g.currentPlayerNumber,
+		Round:               g.round,
+		IsLastRound:         g.isLastRound,
+		HiddenAssets:        g.hiddenAssets,
+		Acquirer:            g.corporationIndex(g.Acquirer()),
+		CurrentDefunct:      g.corporationIndex(g.CurrentDefunct()),
+		TiedCorps:           []int{},
+	}
+	if g.lastPlayedTile != nil {
+		view.LastPlayedTile = tile.Coords(g.lastPlayedTile)
+	}
+	for _, corp := range g.TiedCorps() {
+		view.TiedCorps = append(view.TiedCorps, g.corporationIndex(corp))
+	}
+	for i, corp := range g.corporations {
+		view.Corporations[i] = CorporationSnapshot{Size: corp.Size(), Stock: corp.Stock()}
+	}
+
+	for n, pl := range g.players {
+		pv := PlayerView{
+			TileCount: len(pl.Tiles()),
+			Active:    pl.Active(),
+		}
+		if n == playerNumber {
+			pv.Tiles = tilesToCoords(pl.Tiles())
+		}
+		if n == playerNumber || !g.hiddenAssets {
+			cash := pl.Cash()
+			var shares [7]int
+			for i, corp := range g.corporations {
+				shares[i] = pl.Shares(corp)
+			}
+			pv.Cash, pv.Shares = &cash, &shares
+		}
+		view.Players = append(view.Players, pv)
+	}
+	return view, nil
+}
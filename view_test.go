@@ -0,0 +1,112 @@
+package acquire
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/svera/acquire/corporation"
+	"github.com/svera/acquire/tile"
+)
+
+func TestViewForRedactsRivalsTiles(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 7})
+	playTurns(game, 20)
+	view, err := game.ViewFor(1)
+	if err != nil {
+		t.Fatalf("View must be returned for an existing player, got error %s", err)
+	}
+	if len(view.Players) != 3 {
+		t.Fatalf("View must describe all 3 players, got %d", len(view.Players))
+	}
+	own := view.Players[1]
+	if strings.Join(own.Tiles, ",") != strings.Join(tilesToCoords(game.Player(1).Tiles()), ",") {
+		t.Errorf("View must show the player's own tiles %v, got %v", tilesToCoords(game.Player(1).Tiles()), own.Tiles)
+	}
+	for _, n := range []int{0, 2} {
+		rival := view.Players[n]
+		if rival.Tiles != nil {
+			t.Errorf("View must not show tiles of player %d, got %v", n, rival.Tiles)
+		}
+		if rival.TileCount != len(game.Player(n).Tiles()) {
+			t.Errorf("View must show how many tiles player %d has, expected %d, got %d", n, len(game.Player(n).Tiles()), rival.TileCount)
+		}
+		if rival.Cash == nil || *rival.Cash != game.Player(n).Cash() {
+			t.Errorf("View must show cash of player %d when assets are open, got %v", n, rival.Cash)
+		}
+		if rival.Shares == nil {
+			t.Errorf("View must show shares of player %d when assets are open", n)
+		}
+	}
+	if view.TilesLeft != len(game.tileset.Tiles()) {
+		t.Errorf("View must show %d tiles left, got %d", len(game.tileset.Tiles()), view.TilesLeft)
+	}
+}
+
+func TestViewForHiddenAssets(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 7, HiddenAssets: true})
+	playTurns(game, 20)
+	game.Player(0).AddShares(game.Corporations()[0], 3)
+	view, _ := game.ViewFor(0)
+	if view.Players[0].Cash == nil || *view.Players[0].Cash != game.Player(0).Cash() {
+		t.Errorf("View must show the player's own cash, got %v", view.Players[0].Cash)
+	}
+	if view.Players[0].Shares == nil || view.Players[0].Shares[0] != game.Player(0).Shares(game.Corporations()[0]) {
+		t.Errorf("View must show the player's own shares, got %v", view.Players[0].Shares)
+	}
+	for _, n := range []int{1, 2} {
+		if view.Players[n].Cash != nil || view.Players[n].Shares != nil {
+			t.Errorf("View must not show cash nor shares of player %d in hidden assets games", n)
+		}
+	}
+
+	data, _ := json.Marshal(view)
+	for _, tl := range game.Player(1).Tiles() {
+		if strings.Contains(string(data), `"`+tile.Coords(tl)+`"`) {
+			t.Errorf("Encoded view must not contain rivals' tiles, found %s", tile.Coords(tl))
+		}
+	}
+}
+
+func TestViewForInexistentPlayer(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 7})
+	for _, n := range []int{-1, 3} {
+		if _, err := game.ViewFor(n); err == nil || err.Error() != PlayerNotFound {
+			t.Errorf("View for player %d must fail with error %s, got %v", n, PlayerNotFound, err)
+		}
+	}
+}
+
+func TestBankShares(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 7, HiddenAssets: true})
+	corp := game.Corporations()[2]
+	corp.RemoveStock(4)
+	if shares, err := game.BankShares(corp); err != nil || shares != 21 {
+		t.Errorf("Bank must have %d shares left, got %d and error %v", 21, shares, err)
+	}
+	if _, err := game.BankShares(corporation.New()); err == nil || err.Error() != CorporationNotFound {
+		t.Errorf("Asking about a corporation not in the game must fail with error %s, got %v", CorporationNotFound, err)
+	}
+}
+
+func TestHiddenAssetsIsKept(t *testing.T) {
+	game, _ := New(newDefaultPlayers(3), Optional{Seed: 7, HiddenAssets: true})
+	playTurns(game, 10)
+	if !game.HiddenAssets() {
+		t.Fatalf("Game must have hidden assets")
+	}
+	var snapshot Snapshot
+	data, _ := json.Marshal(game.Snapshot())
+	json.Unmarshal(data, &snapshot)
+	restored, err := Restore(snapshot, newDefaultPlayers(3), Optional{})
+	if err != nil || !restored.HiddenAssets() {
+		t.Errorf("Game restored from an encoded snapshot must keep hidden assets, got error %v", err)
+	}
+	var log Log
+	data, _ = json.Marshal(game.Log())
+	json.Unmarshal(data, &log)
+	replayed, err := Replay(log)
+	if err != nil || !replayed.HiddenAssets() {
+		t.Errorf("Game replayed from an encoded log must keep hidden assets, got error %v", err)
+	}
+}